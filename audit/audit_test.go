@@ -0,0 +1,82 @@
+package audit
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestAppendConcurrently(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+
+	// Each writer opens its own log, like concurrent runs of the tool
+	const writers, records = 8, 50
+	var wg sync.WaitGroup
+	for i := range writers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			auditLog, err := Open(path)
+			if err != nil {
+				t.Errorf("Open failed: %v", err)
+				return
+			}
+			defer auditLog.Close()
+			for j := range records {
+				if err := auditLog.Append(&Record{
+					Timestamp:    time.Now(),
+					Organization: "org1",
+					Repository:   strings.Repeat("r", i*10+j),
+					Property:     "team",
+					NewValue:     "backend",
+					Result:       ResultSuccess,
+				}); err != nil {
+					t.Errorf("Append failed: %v", err)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read log: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if len(lines) != writers*records {
+		t.Fatalf("expected %d records, got %d", writers*records, len(lines))
+	}
+	for _, line := range lines {
+		var record Record
+		if err := json.Unmarshal([]byte(line), &record); err != nil {
+			t.Fatalf("invalid record %q: %v", line, err)
+		}
+	}
+}
+
+func TestOpenInvalidPath(t *testing.T) {
+	if _, err := Open(filepath.Join(t.TempDir(), "missing", "audit.jsonl")); err == nil {
+		t.Error("expected error for a missing directory")
+	}
+}
+
+func TestRead(t *testing.T) {
+	records, err := Read(strings.NewReader(`{"run_id":"run1","repository":"repo1","result":"success"}
+
+{"run_id":"run2","repository":"repo2","result":"error"}
+`))
+	if err != nil {
+		t.Fatalf("Read failed: %v", err)
+	}
+	if len(records) != 2 || records[0].RunID != "run1" || records[1].Repository != "repo2" {
+		t.Errorf("unexpected records %+v", records)
+	}
+
+	if _, err := Read(strings.NewReader("{\"run_id\":\"run1\"}\nnot json\n")); err == nil || !strings.Contains(err.Error(), "line 2") {
+		t.Errorf("expected an error for line 2, got %v", err)
+	}
+}
@@ -0,0 +1,201 @@
+package client
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/go-github/v74/github"
+	"golang.org/x/oauth2"
+)
+
+const (
+	// appJWTLifetime is kept below the 10 minute maximum accepted by GitHub
+	appJWTLifetime = 9 * time.Minute
+	// appJWTClockSkew backdates the JWT to tolerate clock drift
+	appJWTClockSkew = 60 * time.Second
+	// installationTokenEarlyExpiry refreshes installation tokens well before they expire,
+	// so that requests in long applies never use a token that expires mid-flight
+	installationTokenEarlyExpiry = 5 * time.Minute
+)
+
+// NewAppClient creates a client that authenticates as a GitHub App. The app JWT is
+// exchanged for an installation token per organization, and installation tokens are
+// refreshed automatically before they expire.
+func NewAppClient(ctx context.Context, appID int64, privateKeyPEM []byte, options Options) (*Client, error) {
+	privateKey, err := parsePrivateKey(privateKeyPEM)
+	if err != nil {
+		return nil, err
+	}
+
+	httpClient, err := options.httpClient()
+	if err != nil {
+		return nil, err
+	}
+	ctx = context.WithValue(ctx, oauth2.HTTPClient, httpClient)
+
+	// JWTs and installation tokens rotate, so responses are cached per app and installation
+	appHTTPClient := oauth2.NewClient(ctx, newAppTokenSource(appID, privateKey))
+	appHTTPClient.Transport = withCacheIdentity(appHTTPClient.Transport, fmt.Sprintf("app:%d", appID))
+	appClient, err := options.newGitHubClient(appHTTPClient)
+	if err != nil {
+		return nil, err
+	}
+	newClient := func(httpClient *http.Client) *github.Client {
+		// The URLs were already validated when creating the app client
+		githubClient, _ := options.newGitHubClient(httpClient)
+		return githubClient
+	}
+	return &Client{
+		githubClient:  appClient,
+		installations: newInstallationClients(ctx, appClient, newClient),
+	}, nil
+}
+
+func parsePrivateKey(privateKeyPEM []byte) (*rsa.PrivateKey, error) {
+	block, _ := pem.Decode(privateKeyPEM)
+	if block == nil {
+		return nil, fmt.Errorf("failed to decode private key: no PEM data found")
+	}
+
+	if privateKey, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return privateKey, nil
+	}
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse private key: %w", err)
+	}
+	privateKey, ok := key.(*rsa.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("failed to parse private key: not an RSA key")
+	}
+	return privateKey, nil
+}
+
+// appTokenSource issues JWTs signed with the private key of a GitHub App
+type appTokenSource struct {
+	appID      int64
+	privateKey *rsa.PrivateKey
+	now        func() time.Time
+}
+
+func newAppTokenSource(appID int64, privateKey *rsa.PrivateKey) oauth2.TokenSource {
+	return oauth2.ReuseTokenSourceWithExpiry(nil, &appTokenSource{
+		appID:      appID,
+		privateKey: privateKey,
+		now:        time.Now,
+	}, time.Minute)
+}
+
+func (s *appTokenSource) Token() (*oauth2.Token, error) {
+	now := s.now()
+	expiry := now.Add(appJWTLifetime)
+	jwt, err := signAppJWT(s.appID, s.privateKey, now.Add(-appJWTClockSkew), expiry)
+	if err != nil {
+		return nil, err
+	}
+	return &oauth2.Token{AccessToken: jwt, TokenType: "Bearer", Expiry: expiry}, nil
+}
+
+// signAppJWT creates an RS256 JWT identifying the GitHub App
+func signAppJWT(appID int64, privateKey *rsa.PrivateKey, issuedAt, expiresAt time.Time) (string, error) {
+	header, err := json.Marshal(map[string]string{"alg": "RS256", "typ": "JWT"})
+	if err != nil {
+		return "", fmt.Errorf("failed to encode JWT header: %w", err)
+	}
+	claims, err := json.Marshal(map[string]any{
+		"iat": issuedAt.Unix(),
+		"exp": expiresAt.Unix(),
+		"iss": strconv.FormatInt(appID, 10),
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to encode JWT claims: %w", err)
+	}
+
+	signingInput := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(claims)
+	digest := sha256.Sum256([]byte(signingInput))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, privateKey, crypto.SHA256, digest[:])
+	if err != nil {
+		return "", fmt.Errorf("failed to sign JWT: %w", err)
+	}
+
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
+
+// installationTokenSource exchanges the app JWT for an installation token
+type installationTokenSource struct {
+	ctx            context.Context
+	appClient      *github.Client
+	installationID int64
+}
+
+func (s *installationTokenSource) Token() (*oauth2.Token, error) {
+	installationToken, _, err := s.appClient.Apps.CreateInstallationToken(s.ctx, s.installationID, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create installation token for installation %d: %w", s.installationID, err)
+	}
+	return &oauth2.Token{
+		AccessToken: installationToken.GetToken(),
+		TokenType:   "Bearer",
+		Expiry:      installationToken.GetExpiresAt().Time,
+	}, nil
+}
+
+// installationClients holds one client per organization, each authenticated with
+// the installation token of the GitHub App in that organization
+type installationClients struct {
+	ctx       context.Context
+	appClient *github.Client
+	newClient func(*http.Client) *github.Client
+
+	mu      sync.Mutex
+	clients map[string]*github.Client
+}
+
+func newInstallationClients(ctx context.Context, appClient *github.Client, newClient func(*http.Client) *github.Client) *installationClients {
+	return &installationClients{
+		ctx:       ctx,
+		appClient: appClient,
+		newClient: newClient,
+		clients:   make(map[string]*github.Client),
+	}
+}
+
+func (i *installationClients) clientFor(ctx context.Context, org string) (*github.Client, error) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	key := strings.ToLower(org)
+	if githubClient, exists := i.clients[key]; exists {
+		return githubClient, nil
+	}
+
+	installation, _, err := i.appClient.Apps.FindOrganizationInstallation(ctx, org)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find GitHub App installation for organization %s: %w", org, err)
+	}
+
+	tokenSource := oauth2.ReuseTokenSourceWithExpiry(nil, &installationTokenSource{
+		ctx:            i.ctx,
+		appClient:      i.appClient,
+		installationID: installation.GetID(),
+	}, installationTokenEarlyExpiry)
+	httpClient := oauth2.NewClient(i.ctx, tokenSource)
+	httpClient.Transport = withCacheIdentity(httpClient.Transport, fmt.Sprintf("installation:%d", installation.GetID()))
+	githubClient := i.newClient(httpClient)
+	i.clients[key] = githubClient
+
+	return githubClient, nil
+}
@@ -0,0 +1,182 @@
+package client
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-github/v74/github"
+	"golang.org/x/oauth2"
+)
+
+func generateTestKey(t *testing.T) *rsa.PrivateKey {
+	t.Helper()
+	privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	return privateKey
+}
+
+func TestParsePrivateKey(t *testing.T) {
+	privateKey := generateTestKey(t)
+
+	pkcs8, err := x509.MarshalPKCS8PrivateKey(privateKey)
+	if err != nil {
+		t.Fatalf("failed to marshal key: %v", err)
+	}
+
+	testCases := []struct {
+		name        string
+		pem         []byte
+		expectError bool
+	}{
+		{"PKCS1", pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(privateKey)}), false},
+		{"PKCS8", pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: pkcs8}), false},
+		{"not PEM", []byte("not a key"), true},
+		{"invalid key", pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: []byte("garbage")}), true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			parsed, err := parsePrivateKey(tc.pem)
+			if tc.expectError {
+				if err == nil {
+					t.Error("expected error but got none")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !parsed.Equal(privateKey) {
+				t.Error("parsed key does not match")
+			}
+		})
+	}
+}
+
+func TestSignAppJWT(t *testing.T) {
+	privateKey := generateTestKey(t)
+	issuedAt := time.Unix(1700000000, 0)
+	expiresAt := issuedAt.Add(appJWTLifetime)
+
+	jwt, err := signAppJWT(12345, privateKey, issuedAt, expiresAt)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	parts := strings.Split(jwt, ".")
+	if len(parts) != 3 {
+		t.Fatalf("expected 3 JWT parts, got %d", len(parts))
+	}
+
+	claimsJSON, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		t.Fatalf("failed to decode claims: %v", err)
+	}
+	var claims map[string]any
+	if err := json.Unmarshal(claimsJSON, &claims); err != nil {
+		t.Fatalf("failed to unmarshal claims: %v", err)
+	}
+	if claims["iss"] != "12345" {
+		t.Errorf("expected iss 12345, got %v", claims["iss"])
+	}
+	if claims["iat"] != float64(issuedAt.Unix()) || claims["exp"] != float64(expiresAt.Unix()) {
+		t.Errorf("unexpected iat/exp claims: %v", claims)
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		t.Fatalf("failed to decode signature: %v", err)
+	}
+	digest := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+	if err := rsa.VerifyPKCS1v15(&privateKey.PublicKey, crypto.SHA256, digest[:], signature); err != nil {
+		t.Errorf("signature verification failed: %v", err)
+	}
+}
+
+func TestInstallationClients(t *testing.T) {
+	tokenRequests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.URL.Path == "/orgs/test-org/installation":
+			if r.Header.Get("Authorization") != "Bearer app-jwt" {
+				t.Errorf("expected app JWT, got %q", r.Header.Get("Authorization"))
+			}
+			_, _ = w.Write([]byte(`{"id": 42}`))
+		case r.URL.Path == "/app/installations/42/access_tokens" && r.Method == "POST":
+			tokenRequests++
+			expiresAt := time.Now().Add(time.Hour).UTC().Format(time.RFC3339)
+			_, _ = w.Write([]byte(`{"token": "installation-token", "expires_at": "` + expiresAt + `"}`))
+		case r.URL.Path == "/repos/test-org/test-repo":
+			if r.Header.Get("Authorization") != "Bearer installation-token" {
+				t.Errorf("expected installation token, got %q", r.Header.Get("Authorization"))
+			}
+			_, _ = w.Write([]byte(`{"id": 1, "name": "test-repo", "owner": {"login": "test-org"}}`))
+		case r.URL.Path == "/orgs/other-org/installation":
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"message": "Not Found"}`))
+		default:
+			t.Errorf("unexpected request: %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	ctx := context.Background()
+	newClient := func(httpClient *http.Client) *github.Client {
+		githubClient := github.NewClient(httpClient)
+		githubClient.BaseURL = mustParseURL(server.URL + "/")
+		return githubClient
+	}
+	appClient := newClient(oauth2.NewClient(ctx, oauth2.StaticTokenSource(&oauth2.Token{AccessToken: "app-jwt"})))
+	c := &Client{
+		githubClient:  appClient,
+		installations: newInstallationClients(ctx, appClient, newClient),
+	}
+
+	// The installation token is reused across requests until it nears expiry
+	for range 2 {
+		repo := c.GetRepository(ctx, "test-org", "test-repo")
+		if repo == nil {
+			t.Fatal("GetRepository returned nil")
+		}
+	}
+	if tokenRequests != 1 {
+		t.Errorf("expected 1 installation token request, got %d", tokenRequests)
+	}
+
+	if err := c.UpdateCustomProperties(ctx, "other-org", "repo", map[string]string{"team": "backend"}); err == nil {
+		t.Error("expected error for organization without installation")
+	}
+}
+
+func TestNewAppClient(t *testing.T) {
+	privateKey := generateTestKey(t)
+	privateKeyPEM := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(privateKey)})
+
+	c, err := NewAppClient(context.Background(), 12345, privateKeyPEM, Options{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if c.githubClient == nil || c.installations == nil {
+		t.Fatal("app client is not initialized")
+	}
+
+	if _, err := NewAppClient(context.Background(), 12345, []byte("invalid"), Options{}); err == nil {
+		t.Error("expected error for invalid private key")
+	}
+}
@@ -0,0 +1,353 @@
+package client
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// DefaultCacheTTL is how long cached responses are kept by default
+const DefaultCacheTTL = 24 * time.Hour
+
+// Cache is an on-disk cache of GitHub API responses. Cached responses are never
+// served without asking GitHub first: requests carry the stored ETag or
+// Last-Modified date, and a 304 Not Modified response, which does not count against
+// the rate limit, is answered from the cache.
+type Cache struct {
+	dir string
+	ttl time.Duration
+	now func() time.Time
+}
+
+// NewCache creates a cache storing responses in dir. Entries older than ttl are
+// discarded and fetched again unconditionally.
+func NewCache(dir string, ttl time.Duration) *Cache {
+	return &Cache{dir: dir, ttl: ttl, now: time.Now}
+}
+
+// DefaultCacheDir returns the cache directory in the user cache directory
+func DefaultCacheDir() (string, error) {
+	cacheDir, err := os.UserCacheDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to find the user cache directory: %w", err)
+	}
+	return filepath.Join(cacheDir, "gh-custom-property-manager"), nil
+}
+
+// cacheEntry is a cached response together with the resources it describes
+type cacheEntry struct {
+	URL          string    `json:"url"`
+	StoredAt     time.Time `json:"stored_at"`
+	Organization string    `json:"organization,omitempty"`
+	Repository   string    `json:"repository,omitempty"`
+	Response     []byte    `json:"response"`
+	// Location links to the entry, relative to the cache directory, when it is kept
+	// in the directory of a resource that the request URL does not name
+	Location string `json:"location,omitempty"`
+}
+
+// Transport returns a round tripper caching the responses of base
+func (c *Cache) Transport(base http.RoundTripper) http.RoundTripper {
+	return &cacheTransport{cache: c, base: base}
+}
+
+type cacheTransport struct {
+	cache *Cache
+	base  http.RoundTripper
+}
+
+func (t *cacheTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Method != http.MethodGet {
+		resp, err := t.base.RoundTrip(req)
+		if err == nil && resp.StatusCode < http.StatusBadRequest {
+			t.cache.invalidate(req)
+		}
+		return resp, err
+	}
+
+	key := cacheKey(req)
+	path := t.cache.requestPath(req, key)
+	entry, cached := t.cache.load(path)
+	if !cached {
+		resp, err := t.base.RoundTrip(req)
+		if err != nil {
+			return nil, err
+		}
+		return t.cache.store(key, req, resp)
+	}
+
+	cachedResp, err := http.ReadResponse(bufio.NewReader(bytes.NewReader(entry.Response)), req)
+	if err != nil {
+		t.cache.remove(path)
+		return t.base.RoundTrip(req)
+	}
+
+	conditionalReq := req.Clone(req.Context())
+	if etag := cachedResp.Header.Get("ETag"); etag != "" {
+		conditionalReq.Header.Set("If-None-Match", etag)
+	}
+	if lastModified := cachedResp.Header.Get("Last-Modified"); lastModified != "" {
+		conditionalReq.Header.Set("If-Modified-Since", lastModified)
+	}
+
+	resp, err := t.base.RoundTrip(conditionalReq)
+	if err != nil {
+		cachedResp.Body.Close()
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusNotModified {
+		cachedResp.Body.Close()
+		return t.cache.store(key, req, resp)
+	}
+
+	// Serve the cached body with the fresh headers of the 304, e.g. the rate limit
+	resp.Body.Close()
+	for name, values := range resp.Header {
+		if name != "Content-Length" {
+			cachedResp.Header[name] = values
+		}
+	}
+	return cachedResp, nil
+}
+
+// cacheIdentityKey is the context key of the identity requests are cached under
+type cacheIdentityKey struct{}
+
+// withCacheIdentity returns a round tripper caching the requests of base under a
+// stable identity instead of their Authorization header. Credentials that rotate,
+// such as GitHub App installation tokens, then keep their cache entries.
+func withCacheIdentity(base http.RoundTripper, identity string) http.RoundTripper {
+	return &cacheIdentityTransport{base: base, identity: identity}
+}
+
+type cacheIdentityTransport struct {
+	base     http.RoundTripper
+	identity string
+}
+
+func (t *cacheIdentityTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	return t.base.RoundTrip(req.WithContext(context.WithValue(req.Context(), cacheIdentityKey{}, t.identity)))
+}
+
+// cacheKey identifies a response. GitHub varies responses by the Accept and
+// Authorization headers, so tokens never share cache entries. Requests with a cache
+// identity are keyed by it rather than by their token.
+func cacheKey(req *http.Request) string {
+	identity := req.Header.Get("Authorization")
+	if cacheIdentity, ok := req.Context().Value(cacheIdentityKey{}).(string); ok {
+		identity = cacheIdentity
+	}
+	hash := sha256.New()
+	fmt.Fprintf(hash, "%s\n%s\n%s", identity, req.Header.Get("Accept"), req.URL.String())
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// resourceDir returns the directory of the cached responses describing a repository
+// ("org/repo") or, if repository is empty, an organization. Keeping them apart means
+// changes only touch the entries of the resources they affect. It returns an empty
+// string for names that cannot be used as directories.
+func (c *Cache) resourceDir(organization, repository string) string {
+	if repository != "" {
+		organization, name, _ := strings.Cut(repository, "/")
+		if !isPathSegment(organization) || !isPathSegment(name) {
+			return ""
+		}
+		return filepath.Join(c.dir, strings.ToLower(organization), strings.ToLower(name))
+	}
+	if !isPathSegment(organization) {
+		return ""
+	}
+	return filepath.Join(c.dir, strings.ToLower(organization))
+}
+
+func isPathSegment(name string) bool {
+	return name != "" && name != "." && name != ".." && !strings.ContainsAny(name, `/\`)
+}
+
+// entryPath returns the path of a cached response in the directory of its resource,
+// or at the top of the cache for responses about no organization
+func (c *Cache) entryPath(organization, repository, key string) string {
+	dir := c.resourceDir(organization, repository)
+	if dir == "" {
+		dir = c.dir
+	}
+	return filepath.Join(dir, key+".json")
+}
+
+// requestPath returns the path of the cached response of a request
+func (c *Cache) requestPath(req *http.Request, key string) string {
+	organization, repository := resourceOf(req.URL.Path)
+	return c.entryPath(organization, repository, key)
+}
+
+func readEntry(path string) (*cacheEntry, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var entry cacheEntry
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return nil, err
+	}
+	return &entry, nil
+}
+
+// load returns the cached entry at path unless it is missing or expired
+func (c *Cache) load(path string) (*cacheEntry, bool) {
+	entry, err := readEntry(path)
+	if err == nil && entry.Location != "" {
+		// The linked entry is gone once its repository was invalidated
+		entry, err = readEntry(filepath.Join(c.dir, entry.Location))
+	}
+	if err != nil || (c.ttl > 0 && c.now().Sub(entry.StoredAt) > c.ttl) {
+		c.remove(path)
+		return nil, false
+	}
+	return entry, true
+}
+
+// store caches a successful response with a validator and returns an equivalent
+// response for the caller. Failing to write the cache never fails the request.
+func (c *Cache) store(key string, req *http.Request, resp *http.Response) (*http.Response, error) {
+	if resp.StatusCode != http.StatusOK || (resp.Header.Get("ETag") == "" && resp.Header.Get("Last-Modified") == "") {
+		return resp, nil
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+
+	stored := *resp
+	stored.Body = io.NopCloser(bytes.NewReader(body))
+	stored.ContentLength = int64(len(body))
+	stored.TransferEncoding = nil
+	stored.Header = resp.Header.Clone()
+	stored.Header.Del("Content-Encoding")
+	var dump bytes.Buffer
+	if err := stored.Write(&dump); err != nil {
+		return resp, nil
+	}
+
+	organization, repository := resourceOf(req.URL.Path)
+	if repository == "" && strings.Contains(req.URL.Path, "/repositories/") {
+		// Repositories looked up by ID are named in the response
+		var named struct {
+			FullName string `json:"full_name"`
+		}
+		if json.Unmarshal(body, &named) == nil {
+			repository = named.FullName
+		}
+	}
+
+	data, err := json.Marshal(&cacheEntry{
+		URL:          req.URL.String(),
+		StoredAt:     c.now(),
+		Organization: organization,
+		Repository:   repository,
+		Response:     dump.Bytes(),
+	})
+	if err != nil {
+		return resp, nil
+	}
+	path := c.entryPath(organization, repository, key)
+	if err := writeFileAtomic(path, data); err != nil {
+		return resp, nil
+	}
+
+	// Link the entry from the path of the request, e.g. for a repository looked up by ID
+	if requestPath := c.requestPath(req, key); requestPath != path {
+		location, err := filepath.Rel(c.dir, path)
+		if err != nil {
+			return resp, nil
+		}
+		if link, err := json.Marshal(&cacheEntry{URL: req.URL.String(), StoredAt: c.now(), Location: location}); err == nil {
+			_ = writeFileAtomic(requestPath, link)
+		}
+	}
+	return resp, nil
+}
+
+func (c *Cache) remove(path string) {
+	_ = os.Remove(path)
+}
+
+// invalidate removes the cached responses describing the resources changed by req:
+// the repository and the organization-wide listings containing it
+func (c *Cache) invalidate(req *http.Request) {
+	organization, repository := resourceOf(req.URL.Path)
+	organizationDir := c.resourceDir(organization, "")
+	if organizationDir == "" {
+		return
+	}
+	if repository == "" {
+		_ = os.RemoveAll(organizationDir)
+		return
+	}
+
+	if repositoryDir := c.resourceDir(organization, repository); repositoryDir != "" {
+		_ = os.RemoveAll(repositoryDir)
+	}
+	files, err := filepath.Glob(filepath.Join(organizationDir, "*.json"))
+	if err != nil {
+		return
+	}
+	for _, file := range files {
+		_ = os.Remove(file)
+	}
+}
+
+// resourceOf returns the organization and the repository ("org/repo") an API path
+// refers to. The API prefix of GitHub Enterprise Server, e.g. /api/v3, is ignored.
+func resourceOf(path string) (string, string) {
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+	for i, segment := range segments {
+		switch segment {
+		case "repos":
+			if i+2 < len(segments) {
+				return segments[i+1], segments[i+1] + "/" + segments[i+2]
+			}
+			return "", ""
+		case "orgs":
+			if i+1 < len(segments) {
+				return segments[i+1], ""
+			}
+			return "", ""
+		}
+	}
+	return "", ""
+}
+
+// writeFileAtomic writes a file through a temporary file, so that concurrent runs
+// never read partially written entries
+func writeFileAtomic(path string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".tmp-*")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
@@ -0,0 +1,217 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-github/v74/github"
+)
+
+// newCachedTestClient returns a client for a fake API counting full and conditional
+// responses of the repository endpoint
+func newCachedTestClient(t *testing.T, cache *Cache, full, notModified *int) *Client {
+	t.Helper()
+	team := "backend"
+	mux := http.NewServeMux()
+	mux.HandleFunc("/repos/org1/repo1", func(w http.ResponseWriter, r *http.Request) {
+		etag := fmt.Sprintf(`"%s"`, team)
+		if r.Header.Get("If-None-Match") == etag {
+			*notModified++
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		*full++
+		w.Header().Set("ETag", etag)
+		fmt.Fprintf(w, `{"id": 1, "name": "repo1", "full_name": "org1/repo1", "custom_properties": {"team": "%s"}}`, team)
+	})
+	mux.HandleFunc("/repos/org1/repo1/properties/values", func(w http.ResponseWriter, r *http.Request) {
+		team = "frontend"
+		w.WriteHeader(http.StatusNoContent)
+	})
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	githubClient := github.NewClient(&http.Client{Transport: cache.Transport(http.DefaultTransport)})
+	githubClient.BaseURL = mustParseURL(server.URL + "/")
+	return &Client{githubClient: githubClient}
+}
+
+func TestCacheConditionalRequests(t *testing.T) {
+	cache := NewCache(t.TempDir(), time.Hour)
+	var full, notModified int
+	c := newCachedTestClient(t, cache, &full, &notModified)
+	ctx := context.Background()
+
+	for range 3 {
+		repository := c.GetRepository(ctx, "org1", "repo1")
+		if repository == nil || repository.CustomProperties["team"] != "backend" {
+			t.Fatalf("unexpected repository %v", repository)
+		}
+	}
+	if full != 1 || notModified != 2 {
+		t.Errorf("expected 1 full and 2 conditional responses, got %d and %d", full, notModified)
+	}
+
+	// Updating the repository invalidates its entries
+	if err := c.UpdateCustomProperties(ctx, "org1", "repo1", map[string]string{"team": "frontend"}); err != nil {
+		t.Fatalf("UpdateCustomProperties failed: %v", err)
+	}
+	entries, _ := filepath.Glob(filepath.Join(cache.dir, "org1", "repo1", "*.json"))
+	if len(entries) != 0 {
+		t.Errorf("expected the cache entry to be invalidated, got %v", entries)
+	}
+
+	repository := c.GetRepository(ctx, "org1", "repo1")
+	if repository == nil || repository.CustomProperties["team"] != "frontend" {
+		t.Fatalf("expected the updated repository, got %v", repository)
+	}
+	if full != 2 {
+		t.Errorf("expected a full response after the update, got %d", full)
+	}
+}
+
+func TestCacheInvalidateAffectedEntries(t *testing.T) {
+	cache := NewCache(t.TempDir(), time.Hour)
+	var requests int
+	base := roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		requests++
+		body := `{}`
+		if strings.HasPrefix(req.URL.Path, "/repositories/") {
+			body = `{"full_name": "org1/repo1"}`
+		}
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Header:     http.Header{"Etag": []string{`"1"`}},
+			Body:       io.NopCloser(strings.NewReader(body)),
+			Request:    req,
+		}, nil
+	})
+	transport := cache.Transport(base)
+	send := func(method, path string) {
+		t.Helper()
+		req, _ := http.NewRequest(method, "https://api.github.com"+path, nil)
+		resp, err := transport.RoundTrip(req)
+		if err != nil {
+			t.Fatalf("RoundTrip failed: %v", err)
+		}
+		resp.Body.Close()
+	}
+	cached := func(path string) bool {
+		req, _ := http.NewRequest(http.MethodGet, "https://api.github.com"+path, nil)
+		_, exists := cache.load(cache.requestPath(req, cacheKey(req)))
+		return exists
+	}
+
+	for _, path := range []string{"/repos/org1/repo1", "/repos/org1/repo2", "/orgs/org1/repos", "/repos/org2/repo1", "/repositories/1"} {
+		send(http.MethodGet, path)
+	}
+	if entries, _ := filepath.Glob(filepath.Join(cache.dir, "org1", "repo1", "*.json")); len(entries) != 2 {
+		t.Errorf("expected the repository and its lookup by ID in its directory, got %v", entries)
+	}
+
+	send(http.MethodPatch, "/repos/org1/repo1/properties/values")
+	for path, expected := range map[string]bool{
+		"/repos/org1/repo1": false,
+		"/repositories/1":   false,
+		"/orgs/org1/repos":  false,
+		"/repos/org1/repo2": true,
+		"/repos/org2/repo1": true,
+	} {
+		if cached(path) != expected {
+			t.Errorf("expected %s to be cached: %t", path, expected)
+		}
+	}
+
+	send(http.MethodPatch, "/orgs/org1/properties/values")
+	if cached("/repos/org1/repo2") || !cached("/repos/org2/repo1") {
+		t.Error("expected organization-wide changes to invalidate only the organization")
+	}
+}
+
+func TestCacheTTL(t *testing.T) {
+	cache := NewCache(t.TempDir(), time.Hour)
+	now := time.Now()
+	cache.now = func() time.Time { return now }
+	var full, notModified int
+	c := newCachedTestClient(t, cache, &full, &notModified)
+	ctx := context.Background()
+
+	c.GetRepository(ctx, "org1", "repo1")
+	now = now.Add(2 * time.Hour)
+	c.GetRepository(ctx, "org1", "repo1")
+
+	if full != 2 || notModified != 0 {
+		t.Errorf("expected expired entries to be fetched again, got %d full and %d conditional responses", full, notModified)
+	}
+}
+
+func TestCacheKeyPerToken(t *testing.T) {
+	first, _ := http.NewRequest(http.MethodGet, "https://api.github.com/repos/org1/repo1", nil)
+	first.Header.Set("Authorization", "Bearer token-a")
+	second := first.Clone(context.Background())
+	second.Header.Set("Authorization", "Bearer token-b")
+
+	if cacheKey(first) == cacheKey(second) {
+		t.Error("expected different cache keys for different tokens")
+	}
+}
+
+func TestCacheKeyPerIdentity(t *testing.T) {
+	var keys []string
+	record := roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		keys = append(keys, cacheKey(req))
+		return &http.Response{StatusCode: http.StatusOK, Body: http.NoBody}, nil
+	})
+	// Rotating installation tokens keep the key of their installation
+	for _, token := range []string{"token-a", "token-b"} {
+		req, _ := http.NewRequest(http.MethodGet, "https://api.github.com/repos/org1/repo1", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		if _, err := withCacheIdentity(record, "installation:1").RoundTrip(req); err != nil {
+			t.Fatalf("RoundTrip failed: %v", err)
+		}
+	}
+	req, _ := http.NewRequest(http.MethodGet, "https://api.github.com/repos/org1/repo1", nil)
+	if _, err := withCacheIdentity(record, "installation:2").RoundTrip(req); err != nil {
+		t.Fatalf("RoundTrip failed: %v", err)
+	}
+
+	if keys[0] != keys[1] {
+		t.Error("expected the same cache key for tokens of the same installation")
+	}
+	if keys[0] == keys[2] {
+		t.Error("expected different cache keys for different installations")
+	}
+}
+
+func TestResourceOf(t *testing.T) {
+	tests := []struct {
+		path         string
+		organization string
+		repository   string
+	}{
+		{"/repos/org1/repo1", "org1", "org1/repo1"},
+		{"/api/v3/repos/org1/repo1/properties/values", "org1", "org1/repo1"},
+		{"/orgs/org1/properties/values", "org1", ""},
+		{"/repositories/1", "", ""},
+		{"/graphql", "", ""},
+	}
+	for _, tt := range tests {
+		organization, repository := resourceOf(tt.path)
+		if organization != tt.organization || repository != tt.repository {
+			t.Errorf("resourceOf(%s) = %s, %s; expected %s, %s", tt.path, organization, repository, tt.organization, tt.repository)
+		}
+	}
+}
+
+type roundTripperFunc func(req *http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
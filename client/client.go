@@ -2,6 +2,8 @@ package client
 
 import (
 	"context"
+	"fmt"
+	"sync"
 
 	"github.com/google/go-github/v74/github"
 	"golang.org/x/oauth2"
@@ -9,6 +11,12 @@ import (
 
 type Client struct {
 	githubClient *github.Client
+	// installations is set when authenticating as a GitHub App
+	installations *installationClients
+
+	actorOnce sync.Once
+	actor     string
+	actorErr  error
 }
 
 func NewClient(ctx context.Context, token string) *Client {
@@ -22,8 +30,34 @@ func NewClient(ctx context.Context, token string) *Client {
 	return &Client{githubClient: githubClient}
 }
 
+// clientFor returns the client to use for requests to the organization
+func (c *Client) clientFor(ctx context.Context, org string) (*github.Client, error) {
+	if c.installations == nil {
+		return c.githubClient, nil
+	}
+	return c.installations.clientFor(ctx, org)
+}
+
 func (c *Client) GetRepository(ctx context.Context, org, repo string) *github.Repository {
-	repository, _, err := c.githubClient.Repositories.Get(ctx, org, repo)
+	githubClient, err := c.clientFor(ctx, org)
+	if err != nil {
+		return nil
+	}
+	repository, _, err := githubClient.Repositories.Get(ctx, org, repo)
+	if err != nil {
+		return nil
+	}
+	return repository
+}
+
+// GetRepositoryByID looks up a repository by ID. The organization the repository is
+// expected in selects the credentials to use.
+func (c *Client) GetRepositoryByID(ctx context.Context, org string, id int64) *github.Repository {
+	githubClient, err := c.clientFor(ctx, org)
+	if err != nil {
+		return nil
+	}
+	repository, _, err := githubClient.Repositories.GetByID(ctx, id)
 	if err != nil {
 		return nil
 	}
@@ -33,11 +67,43 @@ func (c *Client) GetRepository(ctx context.Context, org, repo string) *github.Re
 func (c *Client) UpdateCustomProperties(ctx context.Context, org, repo string, properties map[string]string) error {
 	customPropertyValues := make([]*github.CustomPropertyValue, 0, len(properties))
 	for propertyName, propertyValue := range properties {
-		customPropertyValues = append(customPropertyValues, &github.CustomPropertyValue{
+		customPropertyValue := &github.CustomPropertyValue{
 			PropertyName: propertyName,
 			Value:        propertyValue,
-		})
+		}
+		if propertyValue == "" {
+			// An empty value removes the property from the repository
+			customPropertyValue.Value = nil
+		}
+		customPropertyValues = append(customPropertyValues, customPropertyValue)
+	}
+	githubClient, err := c.clientFor(ctx, org)
+	if err != nil {
+		return err
 	}
-	_, err := c.githubClient.Repositories.CreateOrUpdateCustomProperties(ctx, org, repo, customPropertyValues)
+	_, err = githubClient.Repositories.CreateOrUpdateCustomProperties(ctx, org, repo, customPropertyValues)
 	return err
 }
+
+// Actor returns who changes are made as: the login of the token's user, or the bot
+// of the GitHub App
+func (c *Client) Actor(ctx context.Context) (string, error) {
+	c.actorOnce.Do(func() {
+		if c.installations != nil {
+			app, _, err := c.installations.appClient.Apps.Get(ctx, "")
+			if err != nil {
+				c.actorErr = fmt.Errorf("failed to get GitHub App: %w", err)
+				return
+			}
+			c.actor = app.GetSlug() + "[bot]"
+			return
+		}
+		user, _, err := c.githubClient.Users.Get(ctx, "")
+		if err != nil {
+			c.actorErr = fmt.Errorf("failed to get authenticated user: %w", err)
+			return
+		}
+		c.actor = user.GetLogin()
+	})
+	return c.actor, c.actorErr
+}
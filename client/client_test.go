@@ -2,10 +2,12 @@ package client
 
 import (
 	"context"
+	"io"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
 	"strconv"
+	"strings"
 	"testing"
 
 	"github.com/google/go-github/v74/github"
@@ -351,3 +353,25 @@ func TestUpdateCustomProperties_WithSpecialValues(t *testing.T) {
 		t.Errorf("UpdateCustomProperties returned error: %v", err)
 	}
 }
+
+func TestUpdateCustomProperties_EmptyValueUnsets(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			t.Errorf("Failed to read request: %v", err)
+		}
+		if !strings.Contains(string(body), `"value":null`) {
+			t.Errorf("Expected a null value, got %s", body)
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	client := github.NewClient(nil)
+	client.BaseURL = mustParseURL(server.URL + "/")
+	c := &Client{githubClient: client}
+
+	if err := c.UpdateCustomProperties(context.Background(), "test-org", "test-repo", map[string]string{"team": ""}); err != nil {
+		t.Errorf("UpdateCustomProperties returned error: %v", err)
+	}
+}
@@ -0,0 +1,223 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/google/go-github/v74/github"
+)
+
+// ListOrganizations returns the organizations visible to the credentials: the
+// organizations of the authenticated user, or the installations of a GitHub App.
+func (c *Client) ListOrganizations(ctx context.Context) ([]string, error) {
+	if c.installations != nil {
+		return c.installations.organizations(ctx)
+	}
+
+	var organizations []string
+	opts := &github.ListOptions{PerPage: 100}
+	for {
+		orgs, resp, err := c.githubClient.Organizations.List(ctx, "", opts)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list organizations: %w", err)
+		}
+		for _, org := range orgs {
+			organizations = append(organizations, org.GetLogin())
+		}
+		if resp.NextPage == 0 {
+			break
+		}
+		opts.Page = resp.NextPage
+	}
+	return organizations, nil
+}
+
+func (i *installationClients) organizations(ctx context.Context) ([]string, error) {
+	var organizations []string
+	opts := &github.ListOptions{PerPage: 100}
+	for {
+		installations, resp, err := i.appClient.Apps.ListInstallations(ctx, opts)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list GitHub App installations: %w", err)
+		}
+		for _, installation := range installations {
+			if installation.GetAccount().GetType() == "Organization" {
+				organizations = append(organizations, installation.GetAccount().GetLogin())
+			}
+		}
+		if resp.NextPage == 0 {
+			break
+		}
+		opts.Page = resp.NextPage
+	}
+	return organizations, nil
+}
+
+const enterpriseOrganizationsQuery = `query($slug: String!, $cursor: String) {
+  enterprise(slug: $slug) {
+    organizations(first: 100, after: $cursor) {
+      nodes { login }
+      pageInfo { hasNextPage endCursor }
+    }
+  }
+}`
+
+type enterpriseOrganizationsResponse struct {
+	Data struct {
+		Enterprise *struct {
+			Organizations struct {
+				Nodes []struct {
+					Login string `json:"login"`
+				} `json:"nodes"`
+				PageInfo struct {
+					HasNextPage bool   `json:"hasNextPage"`
+					EndCursor   string `json:"endCursor"`
+				} `json:"pageInfo"`
+			} `json:"organizations"`
+		} `json:"enterprise"`
+	} `json:"data"`
+	Errors []struct {
+		Message string `json:"message"`
+	} `json:"errors"`
+}
+
+// ListEnterpriseOrganizations returns the organizations of an enterprise. The
+// enterprise is only available through the GraphQL API.
+func (c *Client) ListEnterpriseOrganizations(ctx context.Context, enterprise string) ([]string, error) {
+	if c.installations != nil {
+		return nil, fmt.Errorf("listing enterprise organizations is not supported with GitHub App authentication")
+	}
+
+	var organizations []string
+	var cursor *string
+	for {
+		body := map[string]any{
+			"query":     enterpriseOrganizationsQuery,
+			"variables": map[string]any{"slug": enterprise, "cursor": cursor},
+		}
+		// The GraphQL endpoint is next to the REST API root, e.g. /api/graphql on GHES
+		req, err := c.githubClient.NewRequest("POST", "../graphql", body)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create GraphQL request: %w", err)
+		}
+
+		var response enterpriseOrganizationsResponse
+		if _, err := c.githubClient.Do(ctx, req, &response); err != nil {
+			return nil, fmt.Errorf("failed to list organizations of enterprise %s: %w", enterprise, err)
+		}
+		if len(response.Errors) > 0 {
+			messages := make([]string, 0, len(response.Errors))
+			for _, graphqlError := range response.Errors {
+				messages = append(messages, graphqlError.Message)
+			}
+			return nil, fmt.Errorf("failed to list organizations of enterprise %s: %s", enterprise, strings.Join(messages, "; "))
+		}
+		if response.Data.Enterprise == nil {
+			return nil, fmt.Errorf("enterprise %s not found", enterprise)
+		}
+
+		for _, node := range response.Data.Enterprise.Organizations.Nodes {
+			organizations = append(organizations, node.Login)
+		}
+		pageInfo := response.Data.Enterprise.Organizations.PageInfo
+		if !pageInfo.HasNextPage {
+			break
+		}
+		cursor = &pageInfo.EndCursor
+	}
+	return organizations, nil
+}
+
+// ListRepositories returns all repositories of an organization together with their
+// custom property values
+func (c *Client) ListRepositories(ctx context.Context, org string) ([]*github.Repository, error) {
+	githubClient, err := c.clientFor(ctx, org)
+	if err != nil {
+		return nil, err
+	}
+
+	var repositories []*github.Repository
+	opts := &github.RepositoryListByOrgOptions{Type: "all", ListOptions: github.ListOptions{PerPage: 100}}
+	for {
+		repos, resp, err := githubClient.Repositories.ListByOrg(ctx, org, opts)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list repositories of organization %s: %w", org, err)
+		}
+		repositories = append(repositories, repos...)
+		if resp.NextPage == 0 {
+			break
+		}
+		opts.Page = resp.NextPage
+	}
+
+	if err := addPropertyValues(ctx, githubClient, org, repositories); err != nil {
+		return nil, err
+	}
+	return repositories, nil
+}
+
+// ListTeamRepositories returns the repositories a team has access to together with
+// their custom property values. The permissions of the team are in the Permissions
+// field of each repository.
+func (c *Client) ListTeamRepositories(ctx context.Context, org, team string) ([]*github.Repository, error) {
+	githubClient, err := c.clientFor(ctx, org)
+	if err != nil {
+		return nil, err
+	}
+
+	var repositories []*github.Repository
+	opts := &github.ListOptions{PerPage: 100}
+	for {
+		repos, resp, err := githubClient.Teams.ListTeamReposBySlug(ctx, org, team, opts)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list repositories of team %s/%s: %w", org, team, err)
+		}
+		// Teams may have access to repositories of other organizations
+		for _, repository := range repos {
+			if strings.EqualFold(repository.GetOwner().GetLogin(), org) {
+				repositories = append(repositories, repository)
+			}
+		}
+		if resp.NextPage == 0 {
+			break
+		}
+		opts.Page = resp.NextPage
+	}
+
+	if err := addPropertyValues(ctx, githubClient, org, repositories); err != nil {
+		return nil, err
+	}
+	return repositories, nil
+}
+
+// addPropertyValues sets the custom property values of repositories of an
+// organization. Values are listed for the whole organization at once.
+func addPropertyValues(ctx context.Context, githubClient *github.Client, org string, repositories []*github.Repository) error {
+	propertyValues := make(map[int64]map[string]any)
+	valueOpts := &github.ListCustomPropertyValuesOptions{ListOptions: github.ListOptions{PerPage: 100}}
+	for {
+		values, resp, err := githubClient.Organizations.ListCustomPropertyValues(ctx, org, valueOpts)
+		if err != nil {
+			return fmt.Errorf("failed to list custom property values of organization %s: %w", org, err)
+		}
+		for _, repositoryValues := range values {
+			properties := make(map[string]any, len(repositoryValues.Properties))
+			for _, property := range repositoryValues.Properties {
+				properties[property.PropertyName] = property.Value
+			}
+			propertyValues[repositoryValues.RepositoryID] = properties
+		}
+		if resp.NextPage == 0 {
+			break
+		}
+		valueOpts.Page = resp.NextPage
+	}
+
+	for _, repository := range repositories {
+		if properties, exists := propertyValues[repository.GetID()]; exists {
+			repository.CustomProperties = properties
+		}
+	}
+	return nil
+}
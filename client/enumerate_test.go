@@ -0,0 +1,110 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/go-github/v74/github"
+)
+
+func newTestClient(t *testing.T, handler http.Handler) *Client {
+	t.Helper()
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	client := github.NewClient(nil)
+	client.BaseURL = mustParseURL(server.URL + "/api/v3/")
+	return &Client{githubClient: client}
+}
+
+func TestListOrganizations(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v3/user/orgs", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("page") == "2" {
+			fmt.Fprint(w, `[{"login": "org2"}]`)
+			return
+		}
+		w.Header().Set("Link", fmt.Sprintf(`<http://%s/api/v3/user/orgs?page=2>; rel="next"`, r.Host))
+		fmt.Fprint(w, `[{"login": "org1"}]`)
+	})
+	c := newTestClient(t, mux)
+
+	organizations, err := c.ListOrganizations(context.Background())
+	if err != nil {
+		t.Fatalf("ListOrganizations failed: %v", err)
+	}
+	if strings.Join(organizations, ",") != "org1,org2" {
+		t.Errorf("expected org1,org2, got %v", organizations)
+	}
+}
+
+func TestListEnterpriseOrganizations(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/graphql", func(w http.ResponseWriter, r *http.Request) {
+		var request struct {
+			Variables struct {
+				Slug   string  `json:"slug"`
+				Cursor *string `json:"cursor"`
+			} `json:"variables"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			t.Errorf("failed to decode request: %v", err)
+		}
+		switch {
+		case request.Variables.Slug != "acme":
+			fmt.Fprint(w, `{"data": {"enterprise": null}}`)
+		case request.Variables.Cursor == nil:
+			fmt.Fprint(w, `{"data": {"enterprise": {"organizations": {"nodes": [{"login": "org1"}], "pageInfo": {"hasNextPage": true, "endCursor": "c1"}}}}}`)
+		default:
+			fmt.Fprint(w, `{"data": {"enterprise": {"organizations": {"nodes": [{"login": "org2"}], "pageInfo": {"hasNextPage": false}}}}}`)
+		}
+	})
+	c := newTestClient(t, mux)
+	ctx := context.Background()
+
+	organizations, err := c.ListEnterpriseOrganizations(ctx, "acme")
+	if err != nil {
+		t.Fatalf("ListEnterpriseOrganizations failed: %v", err)
+	}
+	if strings.Join(organizations, ",") != "org1,org2" {
+		t.Errorf("expected org1,org2, got %v", organizations)
+	}
+
+	if _, err := c.ListEnterpriseOrganizations(ctx, "unknown"); err == nil || !strings.Contains(err.Error(), "enterprise unknown not found") {
+		t.Errorf("expected not found error, got %v", err)
+	}
+}
+
+func TestListRepositories(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v3/orgs/org1/repos", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `[{"id": 1, "name": "repo1", "owner": {"login": "org1"}}, {"id": 2, "name": "repo2", "owner": {"login": "org1"}}]`)
+	})
+	mux.HandleFunc("/api/v3/orgs/org1/properties/values", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `[{"repository_id": 1, "repository_name": "repo1", "properties": [{"property_name": "team", "value": "backend"}]}]`)
+	})
+	c := newTestClient(t, mux)
+
+	repositories, err := c.ListRepositories(context.Background(), "org1")
+	if err != nil {
+		t.Fatalf("ListRepositories failed: %v", err)
+	}
+	if len(repositories) != 2 {
+		t.Fatalf("expected 2 repositories, got %d", len(repositories))
+	}
+	if repositories[0].CustomProperties["team"] != "backend" {
+		t.Errorf("expected team=backend on repo1, got %v", repositories[0].CustomProperties)
+	}
+	if repositories[1].CustomProperties != nil {
+		t.Errorf("expected no custom properties on repo2, got %v", repositories[1].CustomProperties)
+	}
+
+	if _, err := c.ListRepositories(context.Background(), "org2"); err == nil {
+		t.Error("expected error for unknown organization")
+	}
+}
@@ -0,0 +1,176 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-github/v74/github"
+	"github.com/hi120ki/gh-custom-property-manager/githubtest"
+)
+
+func newFakeClient(t *testing.T, server *githubtest.Server, cache *Cache) *Client {
+	t.Helper()
+	c, err := NewClientWithOptions(context.Background(), "test-token", Options{BaseURL: server.URL(), Cache: cache})
+	if err != nil {
+		t.Fatalf("NewClientWithOptions failed: %v", err)
+	}
+	return c
+}
+
+func TestFakeServerPagination(t *testing.T) {
+	server := githubtest.NewServer(t)
+	server.SetPageSize(2)
+	for _, name := range []string{"repo1", "repo2", "repo3", "repo4", "repo5"} {
+		server.AddRepository(githubtest.Repository{Organization: "org1", Name: name, Properties: map[string]any{"team": name}})
+	}
+	c := newFakeClient(t, server, nil)
+
+	repositories, err := c.ListRepositories(context.Background(), "org1")
+	if err != nil {
+		t.Fatalf("ListRepositories failed: %v", err)
+	}
+	if len(repositories) != 5 {
+		t.Fatalf("expected 5 repositories, got %d", len(repositories))
+	}
+	for _, repository := range repositories {
+		if repository.CustomProperties["team"] != repository.GetName() {
+			t.Errorf("expected team=%s, got %v", repository.GetName(), repository.CustomProperties)
+		}
+	}
+}
+
+func TestFakeServerUpdates(t *testing.T) {
+	server := githubtest.NewServer(t)
+	server.DefineProperty("org1", &github.CustomProperty{PropertyName: github.Ptr("team"), ValueType: "single_select", AllowedValues: []string{"backend", "frontend"}})
+	server.AddRepository(githubtest.Repository{Organization: "org1", Name: "repo1", Properties: map[string]any{"team": "backend"}})
+	server.AddRepository(githubtest.Repository{Organization: "org1", Name: "archived", Archived: true})
+	c := newFakeClient(t, server, nil)
+	ctx := context.Background()
+
+	if err := c.UpdateCustomProperties(ctx, "org1", "repo1", map[string]string{"team": "frontend"}); err != nil {
+		t.Fatalf("UpdateCustomProperties failed: %v", err)
+	}
+	if value := server.Repository("org1", "repo1").Properties["team"]; value != "frontend" {
+		t.Errorf("expected team=frontend, got %v", value)
+	}
+
+	// An empty value is sent as null and unsets the property
+	if err := c.UpdateCustomProperties(ctx, "org1", "repo1", map[string]string{"team": ""}); err != nil {
+		t.Fatalf("UpdateCustomProperties failed: %v", err)
+	}
+	if _, exists := server.Repository("org1", "repo1").Properties["team"]; exists {
+		t.Error("expected team to be unset")
+	}
+
+	tests := []struct {
+		name       string
+		repo       string
+		properties map[string]string
+		status     int
+	}{
+		{"value not allowed", "repo1", map[string]string{"team": "mobile"}, http.StatusUnprocessableEntity},
+		{"unknown property", "repo1", map[string]string{"tier": "gold"}, http.StatusUnprocessableEntity},
+		{"archived repository", "archived", map[string]string{"team": "backend"}, http.StatusForbidden},
+		{"missing repository", "missing", map[string]string{"team": "backend"}, http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := c.UpdateCustomProperties(ctx, "org1", tt.repo, tt.properties)
+			var errorResponse *github.ErrorResponse
+			if !errors.As(err, &errorResponse) || errorResponse.Response.StatusCode != tt.status {
+				t.Errorf("expected status %d, got %v", tt.status, err)
+			}
+		})
+	}
+}
+
+func TestFakeServerFailuresAndRateLimit(t *testing.T) {
+	server := githubtest.NewServer(t)
+	server.AddRepository(githubtest.Repository{Organization: "org1", Name: "repo1"})
+	c := newFakeClient(t, server, nil)
+	ctx := context.Background()
+
+	server.FailRequests(http.MethodPatch, "/repos/org1/repo1/properties/values", http.StatusBadGateway, 1)
+	if err := c.UpdateCustomProperties(ctx, "org1", "repo1", map[string]string{"team": "backend"}); err == nil {
+		t.Error("expected the injected failure")
+	}
+	if err := c.UpdateCustomProperties(ctx, "org1", "repo1", map[string]string{"team": "backend"}); err != nil {
+		t.Errorf("expected the retry to succeed, got %v", err)
+	}
+
+	server.SetRateLimit(0)
+	_, err := c.ListRepositories(ctx, "org1")
+	var rateLimitError *github.RateLimitError
+	if !errors.As(err, &rateLimitError) {
+		t.Errorf("expected a rate limit error, got %v", err)
+	}
+}
+
+func TestFakeServerRenamedRepository(t *testing.T) {
+	server := githubtest.NewServer(t)
+	id := server.AddRepository(githubtest.Repository{Organization: "org1", Name: "old-name"})
+	server.RenameRepository("org1", "old-name", "org2", "new-name")
+	c := newFakeClient(t, server, nil)
+
+	repository := c.GetRepository(context.Background(), "org1", "old-name")
+	if repository == nil || repository.GetFullName() != "org2/new-name" || repository.GetID() != id {
+		t.Errorf("expected the redirect to org2/new-name, got %v", repository)
+	}
+}
+
+func TestFakeServerConditionalRequests(t *testing.T) {
+	server := githubtest.NewServer(t)
+	server.AddRepository(githubtest.Repository{Organization: "org1", Name: "repo1", Properties: map[string]any{"team": "backend"}})
+	c := newFakeClient(t, server, NewCache(t.TempDir(), time.Hour))
+	ctx := context.Background()
+
+	// Three requests while only two remain: the conditional one is free
+	server.SetRateLimit(2)
+	for range 2 {
+		if repository := c.GetRepository(ctx, "org1", "repo1"); repository == nil || repository.CustomProperties["team"] != "backend" {
+			t.Fatalf("unexpected repository %v", repository)
+		}
+	}
+	if err := c.UpdateCustomProperties(ctx, "org1", "repo1", map[string]string{"team": "frontend"}); err != nil {
+		t.Fatalf("UpdateCustomProperties failed: %v", err)
+	}
+
+	requests := strings.Join(server.Requests(), "\n")
+	if strings.Count(requests, "GET /repos/org1/repo1") != 2 {
+		t.Errorf("expected two requests for the repository, got %s", requests)
+	}
+}
+
+func TestFakeServerTeamRepositories(t *testing.T) {
+	server := githubtest.NewServer(t)
+	server.SetPageSize(1)
+	server.AddRepository(githubtest.Repository{Organization: "org1", Name: "api", Properties: map[string]any{"team": "platform"}})
+	server.AddRepository(githubtest.Repository{Organization: "org1", Name: "web"})
+	server.AddRepository(githubtest.Repository{Organization: "org1", Name: "docs"})
+	server.AddTeamRepository("org1", "platform", "api", "maintain")
+	server.AddTeamRepository("org1", "platform", "web", "pull")
+	c := newFakeClient(t, server, nil)
+
+	repositories, err := c.ListTeamRepositories(context.Background(), "org1", "platform")
+	if err != nil {
+		t.Fatalf("ListTeamRepositories failed: %v", err)
+	}
+	if len(repositories) != 2 {
+		t.Fatalf("expected 2 repositories, got %d", len(repositories))
+	}
+	api := repositories[0]
+	if api.GetName() != "api" || api.CustomProperties["team"] != "platform" {
+		t.Errorf("expected api with its property values, got %s %v", api.GetName(), api.CustomProperties)
+	}
+	if !api.GetPermissions()["push"] || api.GetPermissions()["admin"] {
+		t.Errorf("expected maintain permissions on api, got %v", api.GetPermissions())
+	}
+
+	if _, err := c.ListTeamRepositories(context.Background(), "org1", "missing"); err == nil {
+		t.Error("expected an error for an unknown team")
+	}
+}
@@ -0,0 +1,148 @@
+package client
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/google/go-github/v74/github"
+	"golang.org/x/oauth2"
+)
+
+// Options configures the GitHub instance and the HTTP transport of a client
+type Options struct {
+	// Hostname of the GitHub instance: github.com (default), a GitHub Enterprise
+	// Server hostname, or a GHE.com data residency subdomain such as acme.ghe.com
+	Hostname string
+	// BaseURL overrides the REST API URL derived from Hostname
+	BaseURL string
+	// UploadURL overrides the upload URL derived from Hostname
+	UploadURL string
+	// CABundleFile is a PEM file with certificate authorities trusted in addition to the system ones
+	CABundleFile string
+	// Proxy is the URL of an HTTPS proxy. When empty, HTTPS_PROXY and related environment variables are used.
+	Proxy string
+	// Cache stores API responses on disk and revalidates them with conditional requests.
+	// Caching is disabled when nil.
+	Cache *Cache
+	// RecordDir is a directory to record sanitized HTTP fixtures to
+	RecordDir string
+	// ReplayDir is a directory of recorded fixtures served instead of contacting GitHub
+	ReplayDir string
+}
+
+// NewClientWithOptions creates a client authenticated with a token for the configured GitHub instance
+func NewClientWithOptions(ctx context.Context, token string, options Options) (*Client, error) {
+	httpClient, err := options.httpClient()
+	if err != nil {
+		return nil, err
+	}
+
+	ctx = context.WithValue(ctx, oauth2.HTTPClient, httpClient)
+	githubClient, err := options.newGitHubClient(oauth2.NewClient(
+		ctx, oauth2.StaticTokenSource(
+			&oauth2.Token{AccessToken: token},
+		),
+	))
+	if err != nil {
+		return nil, err
+	}
+	return &Client{githubClient: githubClient}, nil
+}
+
+// isDefaultHost reports whether the options point at github.com
+func (o Options) isDefaultHost() bool {
+	return o.BaseURL == "" && (o.Hostname == "" || strings.EqualFold(o.Hostname, "github.com"))
+}
+
+// apiURLs returns the REST API and upload URLs of the GitHub instance
+func (o Options) apiURLs() (string, string) {
+	if o.BaseURL != "" {
+		uploadURL := o.UploadURL
+		if uploadURL == "" {
+			uploadURL = o.BaseURL
+		}
+		return o.BaseURL, uploadURL
+	}
+
+	hostname := strings.ToLower(o.Hostname)
+	if strings.HasSuffix(hostname, ".ghe.com") {
+		return fmt.Sprintf("https://api.%s/", hostname), fmt.Sprintf("https://uploads.%s/", hostname)
+	}
+	return fmt.Sprintf("https://%s/api/v3/", hostname), fmt.Sprintf("https://%s/api/uploads/", hostname)
+}
+
+func (o Options) newGitHubClient(httpClient *http.Client) (*github.Client, error) {
+	githubClient := github.NewClient(httpClient)
+	if o.isDefaultHost() {
+		return githubClient, nil
+	}
+
+	baseURL, uploadURL := o.apiURLs()
+	githubClient, err := githubClient.WithEnterpriseURLs(baseURL, uploadURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid GitHub URL %s: %w", baseURL, err)
+	}
+	if o.BaseURL == "" && strings.HasSuffix(strings.ToLower(o.Hostname), ".ghe.com") {
+		// GHE.com serves uploads from a dedicated subdomain without the enterprise path suffix
+		githubClient.UploadURL, err = url.Parse(uploadURL)
+		if err != nil {
+			return nil, fmt.Errorf("invalid GitHub upload URL %s: %w", uploadURL, err)
+		}
+	}
+	return githubClient, nil
+}
+
+// httpClient returns the HTTP client carrying requests, with the custom CA bundle and proxy
+func (o Options) httpClient() (*http.Client, error) {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+
+	if o.CABundleFile != "" {
+		caBundle, err := os.ReadFile(o.CABundleFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CA bundle: %w", err)
+		}
+		rootCAs, err := x509.SystemCertPool()
+		if err != nil {
+			rootCAs = x509.NewCertPool()
+		}
+		if !rootCAs.AppendCertsFromPEM(caBundle) {
+			return nil, fmt.Errorf("failed to parse CA bundle %s: no certificates found", o.CABundleFile)
+		}
+		if transport.TLSClientConfig == nil {
+			transport.TLSClientConfig = &tls.Config{}
+		}
+		transport.TLSClientConfig.RootCAs = rootCAs
+	}
+
+	if o.Proxy != "" {
+		proxyURL, err := url.Parse(o.Proxy)
+		if err != nil {
+			return nil, fmt.Errorf("invalid proxy URL %s: %w", o.Proxy, err)
+		}
+		transport.Proxy = http.ProxyURL(proxyURL)
+	}
+
+	if o.ReplayDir != "" {
+		replayTransport, err := NewReplayTransport(o.ReplayDir)
+		if err != nil {
+			return nil, err
+		}
+		return &http.Client{Transport: replayTransport}, nil
+	}
+
+	var roundTripper http.RoundTripper = transport
+	if o.Cache != nil {
+		roundTripper = o.Cache.Transport(roundTripper)
+	}
+	if o.RecordDir != "" {
+		// Record above the cache, so that fixtures hold full responses rather than 304s
+		roundTripper = NewRecorder(o.RecordDir).Transport(roundTripper)
+	}
+	return &http.Client{Transport: roundTripper}, nil
+}
@@ -0,0 +1,156 @@
+package client
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestOptionsAPIURLs(t *testing.T) {
+	testCases := []struct {
+		name              string
+		options           Options
+		expectedBaseURL   string
+		expectedUploadURL string
+	}{
+		{
+			name:              "github.com",
+			options:           Options{},
+			expectedBaseURL:   "https://api.github.com/",
+			expectedUploadURL: "https://uploads.github.com/",
+		},
+		{
+			name:              "GitHub Enterprise Server",
+			options:           Options{Hostname: "ghe.example.com"},
+			expectedBaseURL:   "https://ghe.example.com/api/v3/",
+			expectedUploadURL: "https://ghe.example.com/api/uploads/",
+		},
+		{
+			name:              "GHE.com data residency",
+			options:           Options{Hostname: "Acme.ghe.com"},
+			expectedBaseURL:   "https://api.acme.ghe.com/",
+			expectedUploadURL: "https://uploads.acme.ghe.com/",
+		},
+		{
+			name:              "custom base URL",
+			options:           Options{BaseURL: "https://github.internal/api/v3/", UploadURL: "https://github.internal/api/uploads/"},
+			expectedBaseURL:   "https://github.internal/api/v3/",
+			expectedUploadURL: "https://github.internal/api/uploads/",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			c, err := NewClientWithOptions(context.Background(), "test-token", tc.options)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if c.githubClient.BaseURL.String() != tc.expectedBaseURL {
+				t.Errorf("expected base URL %s, got %s", tc.expectedBaseURL, c.githubClient.BaseURL)
+			}
+			if c.githubClient.UploadURL.String() != tc.expectedUploadURL {
+				t.Errorf("expected upload URL %s, got %s", tc.expectedUploadURL, c.githubClient.UploadURL)
+			}
+		})
+	}
+}
+
+func TestOptionsCABundle(t *testing.T) {
+	// TLS server with a certificate that is not trusted by the system
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer test-token" {
+			t.Errorf("expected token, got %q", r.Header.Get("Authorization"))
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"id": 1, "name": "test-repo", "owner": {"login": "test-org"}}`))
+	}))
+	defer server.Close()
+
+	caBundleFile := filepath.Join(t.TempDir(), "ca.pem")
+	caBundle := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	if err := os.WriteFile(caBundleFile, caBundle, 0o600); err != nil {
+		t.Fatalf("failed to write CA bundle: %v", err)
+	}
+
+	ctx := context.Background()
+
+	untrusted, err := NewClientWithOptions(ctx, "test-token", Options{BaseURL: server.URL + "/api/v3/"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if repo := untrusted.GetRepository(ctx, "test-org", "test-repo"); repo != nil {
+		t.Error("expected request to fail without the CA bundle")
+	}
+
+	trusted, err := NewClientWithOptions(ctx, "test-token", Options{BaseURL: server.URL + "/api/v3/", CABundleFile: caBundleFile})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if repo := trusted.GetRepository(ctx, "test-org", "test-repo"); repo == nil {
+		t.Error("expected request to succeed with the CA bundle")
+	}
+}
+
+func TestOptionsCABundleWithoutTLSConfig(t *testing.T) {
+	server := httptest.NewTLSServer(http.NotFoundHandler())
+	defer server.Close()
+
+	caBundleFile := filepath.Join(t.TempDir(), "ca.pem")
+	caBundle := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	if err := os.WriteFile(caBundleFile, caBundle, 0o600); err != nil {
+		t.Fatalf("failed to write CA bundle: %v", err)
+	}
+
+	// Without HTTP/2, cloning the default transport does not create a TLS config
+	defaultTransport := http.DefaultTransport
+	http.DefaultTransport = &http.Transport{TLSNextProto: map[string]func(string, *tls.Conn) http.RoundTripper{}}
+	defer func() { http.DefaultTransport = defaultTransport }()
+
+	httpClient, err := Options{CABundleFile: caBundleFile}.httpClient()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	transport, ok := httpClient.Transport.(*http.Transport)
+	if !ok || transport.TLSClientConfig == nil || transport.TLSClientConfig.RootCAs == nil {
+		t.Errorf("expected the CA bundle to be trusted, got transport %#v", httpClient.Transport)
+	}
+}
+
+func TestOptionsInvalid(t *testing.T) {
+	testCases := []struct {
+		name    string
+		options Options
+	}{
+		{"missing CA bundle", Options{CABundleFile: filepath.Join(t.TempDir(), "missing.pem")}},
+		{"invalid proxy", Options{Proxy: "://proxy"}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := NewClientWithOptions(context.Background(), "test-token", tc.options); err == nil {
+				t.Error("expected error but got none")
+			}
+		})
+	}
+}
+
+func TestOptionsProxy(t *testing.T) {
+	httpClient, err := Options{Proxy: "http://proxy.example.com:8080"}.httpClient()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	request, _ := http.NewRequest("GET", "https://api.github.com/", nil)
+	proxyURL, err := httpClient.Transport.(*http.Transport).Proxy(request)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if proxyURL == nil || proxyURL.String() != "http://proxy.example.com:8080" {
+		t.Errorf("expected proxy http://proxy.example.com:8080, got %v", proxyURL)
+	}
+}
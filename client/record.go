@@ -0,0 +1,276 @@
+package client
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// sensitiveHeaders are removed from recorded requests and responses
+var sensitiveHeaders = []string{"Authorization", "Proxy-Authorization", "Cookie", "Set-Cookie"}
+
+// tokenField matches token values in JSON bodies, e.g. installation access tokens
+var tokenField = regexp.MustCompile(`"(token|access_token|refresh_token)"(\s*):(\s*)"[^"]*"`)
+
+// Fixture is a recorded HTTP exchange
+type Fixture struct {
+	Request  FixtureRequest  `json:"request"`
+	Response FixtureResponse `json:"response"`
+}
+
+// FixtureRequest is a recorded request. The URL is stored without scheme, host and
+// the API prefix of GitHub Enterprise Server, so fixtures can be replayed regardless
+// of the GitHub instance they came from.
+type FixtureRequest struct {
+	Method string      `json:"method"`
+	Host   string      `json:"host"`
+	URL    string      `json:"url"`
+	Header http.Header `json:"header,omitempty"`
+	Body   string      `json:"body,omitempty"`
+}
+
+// FixtureResponse is a recorded response
+type FixtureResponse struct {
+	StatusCode int         `json:"status_code"`
+	Header     http.Header `json:"header,omitempty"`
+	Body       string      `json:"body,omitempty"`
+}
+
+// Recorder writes the HTTP traffic of clients to numbered fixture files in a
+// directory. Credentials are stripped before anything is written.
+type Recorder struct {
+	dir string
+
+	mu   sync.Mutex
+	next int
+}
+
+// NewRecorder creates a recorder writing fixtures to dir
+func NewRecorder(dir string) *Recorder {
+	return &Recorder{dir: dir, next: 1}
+}
+
+// Transport returns a round tripper recording the traffic of base
+func (r *Recorder) Transport(base http.RoundTripper) http.RoundTripper {
+	return &recordTransport{recorder: r, base: base}
+}
+
+type recordTransport struct {
+	recorder *Recorder
+	base     http.RoundTripper
+}
+
+func (t *recordTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	var requestBody []byte
+	if req.Body != nil && req.GetBody != nil {
+		body, err := req.GetBody()
+		if err == nil {
+			requestBody, _ = io.ReadAll(body)
+			body.Close()
+		}
+	}
+
+	resp, err := t.base.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	responseBody, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(responseBody))
+
+	fixture := &Fixture{
+		Request: FixtureRequest{
+			Method: req.Method,
+			Host:   req.URL.Host,
+			URL:    apiPath(req.URL.RequestURI()),
+			Header: sanitizeHeader(req.Header),
+			Body:   sanitizeBody(requestBody),
+		},
+		Response: FixtureResponse{
+			StatusCode: resp.StatusCode,
+			Header:     sanitizeHeader(resp.Header),
+			Body:       sanitizeBody(responseBody),
+		},
+	}
+	if err := t.recorder.write(fixture); err != nil {
+		return nil, fmt.Errorf("failed to record %s %s: %w", req.Method, req.URL.Path, err)
+	}
+	return resp, nil
+}
+
+// write stores a fixture in the next free numbered file. Several clients, e.g. for
+// different hosts, may record to the same directory.
+func (r *Recorder) write(fixture *Fixture) error {
+	data, err := json.MarshalIndent(fixture, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := os.MkdirAll(r.dir, 0o755); err != nil {
+		return err
+	}
+	for {
+		file, err := os.OpenFile(filepath.Join(r.dir, fmt.Sprintf("%04d.json", r.next)), os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+		r.next++
+		if errors.Is(err, fs.ErrExist) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if _, err := file.Write(append(data, '\n')); err != nil {
+			file.Close()
+			return err
+		}
+		return file.Close()
+	}
+}
+
+// sanitizeHeader removes credentials and makes Link URLs host-independent
+func sanitizeHeader(header http.Header) http.Header {
+	sanitized := header.Clone()
+	for _, name := range sensitiveHeaders {
+		sanitized.Del(name)
+	}
+	if links := sanitized.Values("Link"); len(links) > 0 {
+		sanitized.Del("Link")
+		for _, link := range links {
+			sanitized.Add("Link", relativeLinks(link))
+		}
+	}
+	if len(sanitized) == 0 {
+		return nil
+	}
+	return sanitized
+}
+
+// relativeLinks strips scheme, host and API prefix from the URLs of a Link header
+func relativeLinks(link string) string {
+	parts := strings.Split(link, ",")
+	for i, part := range parts {
+		start, end := strings.Index(part, "<"), strings.Index(part, ">")
+		if start < 0 || end < start {
+			continue
+		}
+		if u, err := url.Parse(part[start+1 : end]); err == nil {
+			parts[i] = part[:start+1] + apiPath(u.RequestURI()) + part[end:]
+		}
+	}
+	return strings.Join(parts, ",")
+}
+
+// apiPath strips the API prefix of GitHub Enterprise Server from a request URI, e.g.
+// /api/v3/repos/org/repo becomes /repos/org/repo as on github.com
+func apiPath(requestURI string) string {
+	for _, prefix := range []string{"/api/v3", "/api"} {
+		rest, found := strings.CutPrefix(requestURI, prefix)
+		if !found {
+			continue
+		}
+		switch {
+		case rest == "":
+			return "/"
+		case rest[0] == '?':
+			return "/" + rest
+		case rest[0] == '/':
+			return rest
+		}
+	}
+	return requestURI
+}
+
+func sanitizeBody(body []byte) string {
+	return tokenField.ReplaceAllString(string(body), `"$1"$2:$3"REDACTED"`)
+}
+
+// NewReplayTransport returns a round tripper serving the fixtures recorded in dir
+// instead of contacting GitHub. Requests are matched by method and URL without the
+// API prefix, so fixtures of github.com replay against GitHub Enterprise Server and
+// the other way around. Identical requests are answered with their recorded
+// responses in order, and the last one is repeated once they run out.
+func NewReplayTransport(dir string) (http.RoundTripper, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no fixtures found in %s", dir)
+	}
+	sort.Strings(files)
+
+	transport := &replayTransport{fixtures: make(map[string][]*Fixture)}
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read fixture %s: %w", file, err)
+		}
+		var fixture Fixture
+		if err := json.Unmarshal(data, &fixture); err != nil {
+			return nil, fmt.Errorf("failed to parse fixture %s: %w", file, err)
+		}
+		// Fixtures recorded with the API prefix match too
+		key := fixture.Request.Method + " " + apiPath(fixture.Request.URL)
+		transport.fixtures[key] = append(transport.fixtures[key], &fixture)
+	}
+	return transport, nil
+}
+
+type replayTransport struct {
+	mu       sync.Mutex
+	fixtures map[string][]*Fixture
+	served   map[string]int
+}
+
+func (t *replayTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Body != nil {
+		req.Body.Close()
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	key := req.Method + " " + apiPath(req.URL.RequestURI())
+	fixtures := t.fixtures[key]
+	if len(fixtures) == 0 {
+		return nil, fmt.Errorf("no recorded response for %s", key)
+	}
+	if t.served == nil {
+		t.served = make(map[string]int)
+	}
+	fixture := fixtures[min(t.served[key], len(fixtures)-1)]
+	t.served[key]++
+
+	header := fixture.Response.Header.Clone()
+	if header == nil {
+		header = make(http.Header)
+	}
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", fixture.Response.StatusCode, http.StatusText(fixture.Response.StatusCode)),
+		StatusCode:    fixture.Response.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(strings.NewReader(fixture.Response.Body)),
+		ContentLength: int64(len(fixture.Response.Body)),
+		Request:       req,
+	}, nil
+}
@@ -0,0 +1,99 @@
+package client
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/hi120ki/gh-custom-property-manager/githubtest"
+)
+
+func TestRecordAndReplay(t *testing.T) {
+	server := githubtest.NewServer(t)
+	server.RequireToken("secret-token")
+	server.SetPageSize(1)
+	server.AddRepository(githubtest.Repository{Organization: "org1", Name: "repo1", Properties: map[string]any{"team": "backend"}})
+	server.AddRepository(githubtest.Repository{Organization: "org1", Name: "repo2"})
+
+	dir := t.TempDir()
+	ctx := context.Background()
+	recording, err := NewClientWithOptions(ctx, "secret-token", Options{BaseURL: server.URL(), RecordDir: dir})
+	if err != nil {
+		t.Fatalf("NewClientWithOptions failed: %v", err)
+	}
+	recorded, err := recording.ListRepositories(ctx, "org1")
+	if err != nil {
+		t.Fatalf("ListRepositories failed: %v", err)
+	}
+
+	files, _ := filepath.Glob(filepath.Join(dir, "*.json"))
+	if len(files) != 4 {
+		t.Fatalf("expected 4 fixtures (2 pages of repositories and values), got %d", len(files))
+	}
+	for _, file := range files {
+		data, _ := os.ReadFile(file)
+		if strings.Contains(string(data), "secret-token") {
+			t.Errorf("fixture %s contains the token", file)
+		}
+		if strings.Contains(string(data), "<http") {
+			t.Errorf("fixture %s contains absolute Link URLs", file)
+		}
+		if strings.Contains(string(data), "/api/v3") {
+			t.Errorf("fixture %s contains the API prefix", file)
+		}
+	}
+
+	// Replaying needs neither the server nor the token, and works against github.com
+	replaying, err := NewClientWithOptions(ctx, "other-token", Options{BaseURL: "https://api.github.com/", ReplayDir: dir})
+	if err != nil {
+		t.Fatalf("NewClientWithOptions failed: %v", err)
+	}
+	replayed, err := replaying.ListRepositories(ctx, "org1")
+	if err != nil {
+		t.Fatalf("ListRepositories failed: %v", err)
+	}
+	if len(replayed) != len(recorded) {
+		t.Fatalf("expected %d repositories, got %d", len(recorded), len(replayed))
+	}
+	if replayed[0].CustomProperties["team"] != "backend" {
+		t.Errorf("expected team=backend, got %v", replayed[0].CustomProperties)
+	}
+
+	if _, err := replaying.ListRepositories(ctx, "org2"); err == nil || !strings.Contains(err.Error(), "no recorded response") {
+		t.Errorf("expected an error for an unrecorded request, got %v", err)
+	}
+}
+
+func TestSanitize(t *testing.T) {
+	body := sanitizeBody([]byte(`{"token": "ghs_abc", "expires_at": "2025-01-01T00:00:00Z"}`))
+	if body != `{"token": "REDACTED", "expires_at": "2025-01-01T00:00:00Z"}` {
+		t.Errorf("unexpected sanitized body %s", body)
+	}
+
+	link := relativeLinks(`<https://ghe.example.com/api/v3/orgs/org1/repos?page=2>; rel="next", <https://ghe.example.com/api/v3/orgs/org1/repos?page=5>; rel="last"`)
+	if link != `</orgs/org1/repos?page=2>; rel="next", </orgs/org1/repos?page=5>; rel="last"` {
+		t.Errorf("unexpected Link header %s", link)
+	}
+
+	if _, err := NewReplayTransport(t.TempDir()); err == nil {
+		t.Error("expected an error for a directory without fixtures")
+	}
+}
+
+func TestAPIPath(t *testing.T) {
+	tests := map[string]string{
+		"/api/v3/orgs/org1/repos?page=2": "/orgs/org1/repos?page=2",
+		"/api/v3":                        "/",
+		"/api/v3?per_page=100":           "/?per_page=100",
+		"/api/graphql":                   "/graphql",
+		"/orgs/org1/repos":               "/orgs/org1/repos",
+		"/apis/v3/orgs":                  "/apis/v3/orgs",
+	}
+	for requestURI, expected := range tests {
+		if got := apiPath(requestURI); got != expected {
+			t.Errorf("apiPath(%q) = %q, expected %q", requestURI, got, expected)
+		}
+	}
+}
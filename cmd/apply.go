@@ -5,14 +5,19 @@ package cmd
 
 import (
 	"context"
-	"os"
 
-	"github.com/hi120ki/gh-custom-property-manager/client"
 	"github.com/hi120ki/gh-custom-property-manager/config"
 	"github.com/spf13/cobra"
 )
 
-var applyConfigurationFilePaths []string
+var (
+	applyConfigurationFilePaths []string
+	applyLayers                 []string
+	applyVariables              variableFlags
+	applyAuditLogFilePath       string
+	applyRepositoryPolicies     config.RepositoryPolicies
+	applyMaxRemovals            int
+)
 
 // applyCmd represents the apply command
 var applyCmd = &cobra.Command{
@@ -23,34 +28,41 @@ based on the configuration files. It compares the current state with the desired
 and applies the necessary changes.`,
 	Run: func(cmd *cobra.Command, args []string) {
 		ctx := context.Background()
-		githubToken := os.Getenv("GITHUB_TOKEN")
-		if githubToken == "" {
-			cmd.Println("GITHUB_TOKEN environment variable is not set.")
+		if len(applyConfigurationFilePaths) == 0 && len(applyLayers) == 0 {
+			cmd.Println("No configuration files specified. Use --config flag to specify one or more configuration files.")
 			return
 		}
 
-		if len(applyConfigurationFilePaths) == 0 {
-			cmd.Println("No configuration files specified. Use --config flag to specify one or more configuration files.")
+		githubClient, err := newGitHubClient(ctx, cmd)
+		if err != nil {
+			cmd.Printf("Error creating GitHub client: %v\n", err)
 			return
 		}
-
-		githubClient := client.NewClient(ctx, githubToken)
 		configManager := config.NewConfig(githubClient)
+		configManager.SetProgress(progressPrinter(cmd))
+		if err := configManager.SetRepositoryPolicies(applyRepositoryPolicies); err != nil {
+			cmd.Printf("Error: %v\n", err)
+			return
+		}
+		configManager.SetMaxRemovals(applyMaxRemovals)
+		auditLog, err := openAuditLog(cmd, configManager, githubClient, applyAuditLogFilePath)
+		if err != nil {
+			cmd.Printf("Error: %v\n", err)
+			return
+		}
+		if auditLog != nil {
+			defer auditLog.Close()
+		}
 
-		// Load all configuration files
-		for _, configFilePath := range applyConfigurationFilePaths {
-			configFile, err := os.Open(configFilePath)
-			if err != nil {
-				cmd.Printf("Error opening config file %s: %v\n", configFilePath, err)
-				return
-			}
-			defer configFile.Close()
+		if err := setVariables(configManager, applyVariables); err != nil {
+			cmd.Printf("Error: %v\n", err)
+			return
+		}
 
-			if err := configManager.LoadConfig(configFile); err != nil {
-				cmd.Printf("Error loading config from %s: %v\n", configFilePath, err)
-				return
-			}
-			cmd.Printf("Loaded config file: %s\n", configFilePath)
+		// Load all configuration files
+		if err := loadConfigurationFiles(cmd, configManager, applyConfigurationFilePaths, applyLayers); err != nil {
+			cmd.Printf("Error loading configuration: %v\n", err)
+			return
 		}
 
 		// Generate repositories
@@ -65,20 +77,16 @@ and applies the necessary changes.`,
 			cmd.Printf("Error generating diffs: %v\n", err)
 			return
 		}
+		printWarnings(cmd, configManager)
 
 		if len(propertyDiffs) == 0 {
 			cmd.Println("No changes needed.")
 			return
 		}
 
-		cmd.Println("Applying changes:")
-
-		for _, diff := range propertyDiffs {
-			cmd.Printf("  %s/%s: Set %s = %s\n", diff.Organization, diff.Repository, diff.PropertyName, diff.NewValue)
-			if err := configManager.ApplyChange(ctx, diff); err != nil {
-				cmd.Printf("Error applying change: %v\n", err)
-				return
-			}
+		if err := applyDiffs(ctx, cmd, configManager, propertyDiffs); err != nil {
+			cmd.Printf("Error applying change: %v\n", err)
+			return
 		}
 
 		cmd.Println("All changes applied successfully.")
@@ -90,4 +98,9 @@ func init() {
 
 	// Add config flag that can be specified multiple times
 	applyCmd.Flags().StringArrayVar(&applyConfigurationFilePaths, "config", []string{}, "Configuration file paths (can be specified multiple times)")
+	addLayerFlag(applyCmd, &applyLayers)
+	addVariableFlags(applyCmd, &applyVariables)
+	addRepositoryPolicyFlags(applyCmd, &applyRepositoryPolicies)
+	addMaxRemovalsFlag(applyCmd, &applyMaxRemovals)
+	addAuditLogFlag(applyCmd, &applyAuditLogFilePath)
 }
@@ -0,0 +1,102 @@
+/*
+Copyright © 2025 Hi120ki <12624257+hi120ki@users.noreply.github.com>
+*/
+package cmd
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/hi120ki/gh-custom-property-manager/audit"
+	"github.com/hi120ki/gh-custom-property-manager/config"
+	"github.com/spf13/cobra"
+)
+
+// addAuditLogFlag adds the flag for the audit log of applied changes
+func addAuditLogFlag(command *cobra.Command, auditLogFilePath *string) {
+	command.Flags().StringVar(auditLogFilePath, "audit-log", "", "Append a JSON Lines record of each applied change to this file")
+}
+
+// openAuditLog opens the audit log and records every change applied by the config
+// manager to it, under a new run ID. It returns nil when no audit log is configured.
+func openAuditLog(cmd *cobra.Command, configManager *config.Config, githubClient config.GitHubClient, path string) (*audit.Log, error) {
+	if path == "" {
+		return nil, nil
+	}
+	auditLog, err := audit.Open(path)
+	if err != nil {
+		return nil, err
+	}
+
+	runID := audit.NewRunID(time.Now())
+	cmd.Printf("Recording changes to %s with run ID %s\n", path, runID)
+
+	commits := make(map[string]string)
+	configManager.SetApplyHook(func(ctx context.Context, diff *config.PropertyDiff, applyErr error) error {
+		record := &audit.Record{
+			RunID:        runID,
+			Timestamp:    time.Now().UTC(),
+			Actor:        actorFor(ctx, githubClient, diff.Organization),
+			Organization: diff.Organization,
+			Repository:   diff.Repository,
+			Property:     diff.PropertyName,
+			OldValue:     diff.OldValue,
+			NewValue:     diff.NewValue,
+			ConfigFile:   diff.Source,
+			Result:       audit.ResultSuccess,
+		}
+		if diff.Source != "" {
+			dir := filepath.Dir(diff.Source)
+			if _, exists := commits[dir]; !exists {
+				commits[dir] = commitSHA(dir)
+			}
+			record.Commit = commits[dir]
+		}
+		switch {
+		case applyErr != nil:
+			record.Result = audit.ResultError
+			record.Error = applyErr.Error()
+		case diff.Skipped:
+			record.Result = audit.ResultSkipped
+		}
+
+		// A change missing from the audit log stops the run
+		return auditLog.Append(record)
+	})
+	return auditLog, nil
+}
+
+// actorFor returns who changes to the organization are made as, if known
+func actorFor(ctx context.Context, githubClient config.GitHubClient, org string) string {
+	if router, ok := githubClient.(*config.Router); ok {
+		githubClient = router.ClientFor(org)
+	}
+	identified, ok := githubClient.(interface {
+		Actor(ctx context.Context) (string, error)
+	})
+	if !ok {
+		return ""
+	}
+	actor, err := identified.Actor(ctx)
+	if err != nil {
+		return ""
+	}
+	return actor
+}
+
+// commitSHA returns the commit of the configuration files: $GITHUB_SHA in GitHub
+// Actions, and the HEAD of the git repository containing dir otherwise
+func commitSHA(dir string) string {
+	if sha := os.Getenv("GITHUB_SHA"); sha != "" {
+		return sha
+	}
+	output, err := exec.Command("git", "-C", dir, "rev-parse", "HEAD").Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(output))
+}
@@ -0,0 +1,70 @@
+/*
+Copyright © 2025 Hi120ki <12624257+hi120ki@users.noreply.github.com>
+*/
+package cmd
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/go-github/v74/github"
+	"github.com/hi120ki/gh-custom-property-manager/state"
+	"github.com/spf13/cobra"
+)
+
+var (
+	backupOrganizations  []string
+	backupOutputFilePath string
+)
+
+// backupCmd represents the backup command
+var backupCmd = &cobra.Command{
+	Use:   "backup",
+	Short: "Save the custom property values of all repositories of organizations",
+	Long: `Backup command saves the custom property values of every repository of the
+given organizations, including archived ones, to a JSON file. The file can be
+restored with the restore command.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		ctx := context.Background()
+		if len(backupOrganizations) == 0 {
+			cmd.Println("No organizations specified. Use --org flag to specify one or more organizations.")
+			return
+		}
+
+		githubClient, err := newGitHubClient(ctx, cmd)
+		if err != nil {
+			cmd.Printf("Error creating GitHub client: %v\n", err)
+			return
+		}
+		toolSettings, err := loadSettings()
+		if err != nil {
+			cmd.Printf("Error loading settings: %v\n", err)
+			return
+		}
+
+		var repositories []*github.Repository
+		for i, organization := range backupOrganizations {
+			cmd.PrintErrf("Listing repositories of organization %s (%d/%d)\n", organization, i+1, len(backupOrganizations))
+			organizationRepositories, err := githubClient.ListRepositories(ctx, organization)
+			if err != nil {
+				cmd.Printf("Error listing repositories: %v\n", err)
+				return
+			}
+			repositories = append(repositories, organizationRepositories...)
+		}
+
+		snapshot := state.NewBackup(toolSettings.Hostname, backupOrganizations, repositories, time.Now())
+		if err := writeSnapshot(backupOutputFilePath, snapshot); err != nil {
+			cmd.Printf("Error writing backup: %v\n", err)
+			return
+		}
+		cmd.Printf("Saved custom property values of %d repositories to %s\n", len(snapshot.Repositories), backupOutputFilePath)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(backupCmd)
+
+	backupCmd.Flags().StringArrayVar(&backupOrganizations, "org", []string{}, "Organizations to back up (can be specified multiple times)")
+	backupCmd.Flags().StringVarP(&backupOutputFilePath, "output", "o", "backup.json", "Path of the backup file to write")
+}
@@ -0,0 +1,161 @@
+/*
+Copyright © 2025 Hi120ki <12624257+hi120ki@users.noreply.github.com>
+*/
+package cmd
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/hi120ki/gh-custom-property-manager/config"
+	"github.com/spf13/cobra"
+)
+
+var (
+	coverageConfigurationFilePaths []string
+	coverageLayers                 []string
+	coverageVariables              variableFlags
+	coverageOrganizations          []string
+	coverageFormat                 string
+)
+
+// coverageCmd represents the coverage command
+var coverageCmd = &cobra.Command{
+	Use:   "coverage",
+	Short: "Report which repositories of organizations lack custom property values",
+	Long: `Coverage command lists all repositories of the given organizations and compares
+them with the configuration files and their current values. For each configured
+property it reports the percentage of repositories having a value, the repositories
+without a value and the repositories no configuration file mentions. Archived and
+disabled repositories are not counted.
+
+The report is written to stdout as text, JSON or CSV (--format).`,
+	Run: func(cmd *cobra.Command, args []string) {
+		ctx := context.Background()
+		if len(coverageConfigurationFilePaths) == 0 && len(coverageLayers) == 0 {
+			cmd.Println("No configuration files specified. Use --config flag to specify one or more configuration files.")
+			return
+		}
+		if len(coverageOrganizations) == 0 {
+			cmd.Println("No organizations specified. Use --org flag to specify one or more organizations.")
+			return
+		}
+		writeReport, exists := coverageWriters[coverageFormat]
+		if !exists {
+			cmd.Printf("Error: unknown format %s (expected text, json or csv)\n", coverageFormat)
+			return
+		}
+
+		githubClient, err := newGitHubClient(ctx, cmd)
+		if err != nil {
+			cmd.Printf("Error creating GitHub client: %v\n", err)
+			return
+		}
+		configManager := config.NewConfig(githubClient)
+		configManager.SetProgress(progressPrinter(cmd))
+
+		if err := setVariables(configManager, coverageVariables); err != nil {
+			cmd.Printf("Error: %v\n", err)
+			return
+		}
+
+		// Load all configuration files
+		if err := loadConfigurationFiles(cmd, configManager, coverageConfigurationFilePaths, coverageLayers); err != nil {
+			cmd.Printf("Error loading configuration: %v\n", err)
+			return
+		}
+
+		// Generate repositories
+		if err := configManager.GenerateRepositories(ctx); err != nil {
+			cmd.Printf("Error generating repositories: %v\n", err)
+			return
+		}
+
+		coverage, err := configManager.Coverage(ctx, coverageOrganizations)
+		if err != nil {
+			cmd.Printf("Error computing coverage: %v\n", err)
+			return
+		}
+		printWarnings(cmd, configManager)
+
+		if err := writeReport(cmd.OutOrStdout(), coverage); err != nil {
+			cmd.Printf("Error writing report: %v\n", err)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(coverageCmd)
+
+	coverageCmd.Flags().StringArrayVar(&coverageConfigurationFilePaths, "config", []string{}, "Configuration file paths (can be specified multiple times)")
+	addLayerFlag(coverageCmd, &coverageLayers)
+	addVariableFlags(coverageCmd, &coverageVariables)
+	coverageCmd.Flags().StringArrayVar(&coverageOrganizations, "org", []string{}, "Organizations to report on (can be specified multiple times)")
+	coverageCmd.Flags().StringVar(&coverageFormat, "format", "text", "Output format (text, json or csv)")
+}
+
+// coverageWriters write a coverage report in each supported format
+var coverageWriters = map[string]func(w io.Writer, coverage *config.Coverage) error{
+	"text": writeCoverageText,
+	"json": writeCoverageJSON,
+	"csv":  writeCoverageCSV,
+}
+
+func writeCoverageText(w io.Writer, coverage *config.Coverage) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Coverage of %d repositories in %s:\n", coverage.Repositories, strings.Join(coverage.Organizations, ", "))
+	for _, property := range coverage.Properties {
+		fmt.Fprintf(&b, "\n%s: %.1f%% have a value (%d/%d), %d configured\n", property.PropertyName, property.Percentage, property.WithValue, coverage.Repositories, property.Configured)
+		writeRepositoryList(&b, "Without a value", property.Missing)
+		writeRepositoryList(&b, "Not configured", property.Unconfigured)
+	}
+	if len(coverage.Unmanaged) > 0 {
+		b.WriteString("\n")
+		writeRepositoryList(&b, "Not in any configuration file", coverage.Unmanaged)
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func writeRepositoryList(b *strings.Builder, title string, repositories []string) {
+	if len(repositories) == 0 {
+		return
+	}
+	fmt.Fprintf(b, "  %s:\n", title)
+	for _, repository := range repositories {
+		fmt.Fprintf(b, "    %s\n", repository)
+	}
+}
+
+func writeCoverageJSON(w io.Writer, coverage *config.Coverage) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(coverage)
+}
+
+// writeCoverageCSV writes one row per property and repository lacking a value or
+// configuration, after one summary row per property with an empty repository
+func writeCoverageCSV(w io.Writer, coverage *config.Coverage) error {
+	writer := csv.NewWriter(w)
+	_ = writer.Write([]string{"property", "repository", "status", "with_value", "configured", "repositories", "percentage"})
+	total := strconv.Itoa(coverage.Repositories)
+	for _, property := range coverage.Properties {
+		_ = writer.Write([]string{property.PropertyName, "", "summary", strconv.Itoa(property.WithValue), strconv.Itoa(property.Configured), total, strconv.FormatFloat(property.Percentage, 'f', 1, 64)})
+		for _, repository := range property.Missing {
+			_ = writer.Write([]string{property.PropertyName, repository, "missing", "", "", "", ""})
+		}
+		for _, repository := range property.Unconfigured {
+			_ = writer.Write([]string{property.PropertyName, repository, "unconfigured", "", "", "", ""})
+		}
+	}
+	for _, repository := range coverage.Unmanaged {
+		_ = writer.Write([]string{"", repository, "unmanaged", "", "", "", ""})
+	}
+	writer.Flush()
+	return writer.Error()
+}
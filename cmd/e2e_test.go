@@ -0,0 +1,392 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-github/v74/github"
+	"github.com/hi120ki/gh-custom-property-manager/audit"
+	"github.com/hi120ki/gh-custom-property-manager/githubtest"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// execute runs the CLI against the fake server and returns its output
+func execute(t *testing.T, server *githubtest.Server, args ...string) string {
+	t.Helper()
+	t.Setenv("GH_TOKEN", "test-token")
+	t.Setenv("GH_HOST", "")
+
+	resetFlags(rootCmd)
+
+	var output bytes.Buffer
+	rootCmd.SetOut(&output)
+	rootCmd.SetErr(&output)
+	rootCmd.SetArgs(append(args, "--base-url", server.URL(), "--no-cache"))
+	if err := rootCmd.Execute(); err != nil {
+		t.Fatalf("command failed: %v\n%s", err, output.String())
+	}
+	return output.String()
+}
+
+// resetFlags restores the default values of all flags, which cobra keeps between executions
+func resetFlags(command *cobra.Command) {
+	reset := func(flag *pflag.Flag) {
+		if sliceValue, ok := flag.Value.(pflag.SliceValue); ok {
+			_ = sliceValue.Replace(nil)
+		} else {
+			_ = flag.Value.Set(flag.DefValue)
+		}
+		flag.Changed = false
+	}
+	command.Flags().VisitAll(reset)
+	command.PersistentFlags().VisitAll(reset)
+	for _, subcommand := range command.Commands() {
+		resetFlags(subcommand)
+	}
+}
+
+func TestPlanAndApply(t *testing.T) {
+	server := githubtest.NewServer(t)
+	server.RequireToken("test-token")
+	server.AddRepository(githubtest.Repository{Organization: "org1", Name: "repo1", Properties: map[string]any{"team": "frontend"}})
+	server.AddRepository(githubtest.Repository{Organization: "org1", Name: "repo2"})
+	server.AddRepository(githubtest.Repository{Organization: "org1", Name: "repo3", Properties: map[string]any{"team": "backend"}})
+
+	configFilePath := filepath.Join(t.TempDir(), "team.yaml")
+	if err := os.WriteFile(configFilePath, []byte(`property_name: "team"
+values:
+  - value: "backend"
+    repositories:
+      - name: "org1/repo1"
+      - name: "org1/repo2"
+      - name: "org1/repo3"
+`), 0o600); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+
+	output := execute(t, server, "plan", "--config", configFilePath)
+	for _, expected := range []string{
+		"org1/repo1: Change team from frontend to backend",
+		"org1/repo2: Set team = backend",
+	} {
+		if !strings.Contains(output, expected) {
+			t.Errorf("expected plan output to contain %q, got:\n%s", expected, output)
+		}
+	}
+	if strings.Contains(output, "org1/repo3") {
+		t.Errorf("expected no change for org1/repo3, got:\n%s", output)
+	}
+
+	output = execute(t, server, "apply", "--config", configFilePath)
+	if !strings.Contains(output, "All changes applied successfully.") {
+		t.Errorf("expected apply to succeed, got:\n%s", output)
+	}
+	for _, repo := range []string{"repo1", "repo2", "repo3"} {
+		if value := server.Repository("org1", repo).Properties["team"]; value != "backend" {
+			t.Errorf("expected team=backend on org1/%s, got %v", repo, value)
+		}
+	}
+}
+
+func TestApplyAuditLog(t *testing.T) {
+	server := githubtest.NewServer(t)
+	server.SetUser("deploy-bot")
+	server.AddRepository(githubtest.Repository{Organization: "org1", Name: "repo1", Properties: map[string]any{"team": "frontend"}})
+	server.AddRepository(githubtest.Repository{Organization: "org1", Name: "archived", Archived: true})
+	t.Setenv("GITHUB_SHA", "0123456789abcdef")
+
+	dir := t.TempDir()
+	configFilePath := filepath.Join(dir, "team.yaml")
+	if err := os.WriteFile(configFilePath, []byte(`property_name: "team"
+values:
+  - value: "backend"
+    repositories:
+      - name: "org1/repo1"
+      - name: "org1/archived"
+`), 0o600); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+	auditLogFilePath := filepath.Join(dir, "audit.jsonl")
+
+	execute(t, server, "apply", "--config", configFilePath, "--archived", "skip", "--audit-log", auditLogFilePath)
+
+	data, err := os.ReadFile(auditLogFilePath)
+	if err != nil {
+		t.Fatalf("failed to read audit log: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("expected 2 audit records, got %d:\n%s", len(lines), data)
+	}
+
+	results := make(map[string]*audit.Record)
+	for _, line := range lines {
+		var record audit.Record
+		if err := json.Unmarshal([]byte(line), &record); err != nil {
+			t.Fatalf("invalid audit record %s: %v", line, err)
+		}
+		results[record.Repository] = &record
+	}
+
+	record := results["repo1"]
+	if record == nil || record.Result != audit.ResultSuccess || record.Actor != "deploy-bot" || record.OldValue != "frontend" || record.NewValue != "backend" {
+		t.Errorf("unexpected record for repo1: %+v", record)
+	}
+	if record != nil && (record.ConfigFile != configFilePath || record.Commit != "0123456789abcdef") {
+		t.Errorf("expected the config file and commit in the record, got %+v", record)
+	}
+	if record := results["archived"]; record == nil || record.Result != audit.ResultSkipped {
+		t.Errorf("expected a skipped record for the archived repository, got %+v", record)
+	}
+}
+
+func TestRollback(t *testing.T) {
+	server := githubtest.NewServer(t)
+	server.AddRepository(githubtest.Repository{Organization: "org1", Name: "repo1", Properties: map[string]any{"team": "frontend"}})
+	server.AddRepository(githubtest.Repository{Organization: "org1", Name: "repo2"})
+	server.AddRepository(githubtest.Repository{Organization: "org1", Name: "repo3", Properties: map[string]any{"team": "frontend"}})
+
+	dir := t.TempDir()
+	configFilePath := filepath.Join(dir, "team.yaml")
+	if err := os.WriteFile(configFilePath, []byte(`property_name: "team"
+values:
+  - value: "backend"
+    repositories:
+      - name: "org1/repo1"
+      - name: "org1/repo2"
+      - name: "org1/repo3"
+`), 0o600); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+	auditLogFilePath := filepath.Join(dir, "audit.jsonl")
+
+	output := execute(t, server, "apply", "--config", configFilePath, "--audit-log", auditLogFilePath)
+	file, err := os.Open(auditLogFilePath)
+	if err != nil {
+		t.Fatalf("failed to open audit log: %v", err)
+	}
+	records, err := audit.Read(file)
+	file.Close()
+	if err != nil || len(records) != 3 {
+		t.Fatalf("expected 3 audit records, got %d (%v)", len(records), err)
+	}
+	runID := records[0].RunID
+	if runID == "" || !strings.Contains(output, runID) {
+		t.Fatalf("expected the run ID %q to be printed, got:\n%s", runID, output)
+	}
+
+	// A change made after the apply must not be reverted
+	if _, err := server.Client().Repositories.CreateOrUpdateCustomProperties(context.Background(), "org1", "repo3", []*github.CustomPropertyValue{{PropertyName: "team", Value: "platform"}}); err != nil {
+		t.Fatalf("failed to change org1/repo3: %v", err)
+	}
+
+	output = execute(t, server, "rollback", runID, "--audit-log", auditLogFilePath)
+	for _, expected := range []string{
+		"org1/repo1: Change team from backend to frontend",
+		"org1/repo2: Unset team (was backend)",
+		`Warning: skipping org1/repo3: team was changed to "platform" since`,
+		"All changes rolled back successfully.",
+	} {
+		if !strings.Contains(output, expected) {
+			t.Errorf("expected rollback output to contain %q, got:\n%s", expected, output)
+		}
+	}
+	if value := server.Repository("org1", "repo1").Properties["team"]; value != "frontend" {
+		t.Errorf("expected team=frontend on org1/repo1, got %v", value)
+	}
+	if value, exists := server.Repository("org1", "repo2").Properties["team"]; exists {
+		t.Errorf("expected team to be unset on org1/repo2, got %v", value)
+	}
+	if value := server.Repository("org1", "repo3").Properties["team"]; value != "platform" {
+		t.Errorf("expected team=platform on org1/repo3, got %v", value)
+	}
+}
+
+func TestCoverage(t *testing.T) {
+	server := githubtest.NewServer(t)
+	server.AddRepository(githubtest.Repository{Organization: "org1", Name: "repo1", Properties: map[string]any{"team": "backend"}})
+	server.AddRepository(githubtest.Repository{Organization: "org1", Name: "repo2"})
+
+	configFilePath := filepath.Join(t.TempDir(), "team.yaml")
+	if err := os.WriteFile(configFilePath, []byte(`property_name: "team"
+values:
+  - value: "backend"
+    repositories:
+      - name: "org1/repo1"
+`), 0o600); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+
+	output := execute(t, server, "coverage", "--config", configFilePath, "--org", "org1", "--format", "csv")
+	for _, expected := range []string{
+		"team,,summary,1,1,2,50.0",
+		"team,org1/repo2,missing",
+		"team,org1/repo2,unconfigured",
+		",org1/repo2,unmanaged",
+	} {
+		if !strings.Contains(output, expected) {
+			t.Errorf("expected coverage output to contain %q, got:\n%s", expected, output)
+		}
+	}
+}
+
+func TestPlanVariables(t *testing.T) {
+	server := githubtest.NewServer(t)
+	server.AddRepository(githubtest.Repository{Organization: "staging", Name: "service"})
+	server.AddRepository(githubtest.Repository{Organization: "production", Name: "service"})
+
+	dir := t.TempDir()
+	configFilePath := filepath.Join(dir, "team.yaml")
+	if err := os.WriteFile(configFilePath, []byte(`property_name: "team"
+values:
+  - value: "${TEAM}"
+    repositories:
+      - name: "${ORG}/service"
+`), 0o600); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+	varFilePath := filepath.Join(dir, "production.yaml")
+	if err := os.WriteFile(varFilePath, []byte("ORG: production\nTEAM: backend\n"), 0o600); err != nil {
+		t.Fatalf("failed to write variables: %v", err)
+	}
+
+	output := execute(t, server, "plan", "--config", configFilePath, "--var-file", varFilePath, "--var", "TEAM=platform")
+	if !strings.Contains(output, "production/service: Set team = platform") {
+		t.Errorf("expected the production repository with the --var value, got:\n%s", output)
+	}
+
+	output = execute(t, server, "plan", "--config", configFilePath)
+	if !strings.Contains(output, "undefined variable") {
+		t.Errorf("expected an undefined variable error, got:\n%s", output)
+	}
+}
+
+func TestPlanLayersExplain(t *testing.T) {
+	server := githubtest.NewServer(t)
+	server.AddRepository(githubtest.Repository{Organization: "org1", Name: "repo1"})
+	server.AddRepository(githubtest.Repository{Organization: "org1", Name: "repo2"})
+
+	dir := t.TempDir()
+	baseDir := filepath.Join(dir, "base")
+	if err := os.Mkdir(baseDir, 0o700); err != nil {
+		t.Fatalf("failed to create directory: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(baseDir, "team.yaml"), []byte(`property_name: "team"
+values:
+  - value: "platform"
+    repositories:
+      - name: "org1/*"
+`), 0o600); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+	overlayFilePath := filepath.Join(dir, "overlay.yaml")
+	if err := os.WriteFile(overlayFilePath, []byte(`property_name: "team"
+values:
+  - value: "frontend"
+    repositories:
+      - name: "org1/repo1"
+`), 0o600); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+
+	output := execute(t, server, "plan", "--config", baseDir, "--layer", "10="+overlayFilePath, "--explain")
+	for _, expected := range []string{
+		"org1/repo1: Set team = frontend",
+		"from " + overlayFilePath + ":3 (priority 10, org1/repo1)",
+		"overrides platform from " + filepath.Join(baseDir, "team.yaml") + ":3 (priority 0, org1/*)",
+		"org1/repo2: Set team = platform",
+	} {
+		if !strings.Contains(output, expected) {
+			t.Errorf("expected %q in output:\n%s", expected, output)
+		}
+	}
+
+	output = execute(t, server, "plan", "--layer", "high="+overlayFilePath)
+	if !strings.Contains(output, "is not in the format PRIORITY=PATH") {
+		t.Errorf("expected a layer format error, got:\n%s", output)
+	}
+}
+
+func TestExplain(t *testing.T) {
+	server := githubtest.NewServer(t)
+	server.AddRepository(githubtest.Repository{Organization: "org1", Name: "repo1", Properties: map[string]any{"team": "platform"}})
+
+	dir := t.TempDir()
+	configFilePath := filepath.Join(dir, "team.yaml")
+	if err := os.WriteFile(configFilePath, []byte(`property_name: "team"
+values:
+  - value: "platform"
+    repositories:
+      - name: "org1/*"
+  - value: "frontend"
+    repositories:
+      - name: "org1/repo1"
+`), 0o600); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+
+	output := execute(t, server, "explain", "org1/repo1", "--config", configFilePath, "--property", "team")
+	for _, expected := range []string{
+		"desired: frontend",
+		"current: platform (differs)",
+		"from " + configFilePath + ":6 (priority 0, org1/repo1)",
+		"overrides platform from " + configFilePath + ":3 (priority 0, org1/*)",
+	} {
+		if !strings.Contains(output, expected) {
+			t.Errorf("expected %q in output:\n%s", expected, output)
+		}
+	}
+
+	output = execute(t, server, "explain", "org1/repo1", "--config", configFilePath, "--format", "json")
+	var explanation struct {
+		Repository string `json:"repository"`
+		Properties []struct {
+			Property     string `json:"property"`
+			DesiredValue string `json:"desired_value"`
+		} `json:"properties"`
+	}
+	if err := json.Unmarshal([]byte(output[strings.Index(output, "{"):]), &explanation); err != nil {
+		t.Fatalf("invalid JSON output: %v\n%s", err, output)
+	}
+	if explanation.Repository != "org1/repo1" || len(explanation.Properties) != 1 || explanation.Properties[0].DesiredValue != "frontend" {
+		t.Errorf("unexpected explanation %+v", explanation)
+	}
+}
+
+func TestAppSettingsConflict(t *testing.T) {
+	server := githubtest.NewServer(t)
+	t.Setenv("GITHUB_APP_ID", "123")
+	t.Setenv("GITHUB_APP_PRIVATE_KEY", "not parsed before the settings are checked")
+
+	dir := t.TempDir()
+	configFilePath := filepath.Join(dir, "team.yaml")
+	if err := os.WriteFile(configFilePath, []byte(`property_name: "team"
+values:
+  - value: "backend"
+    repositories:
+      - name: "org1/repo1"
+`), 0o600); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+	settingsFilePath := filepath.Join(dir, "settings.yaml")
+	if err := os.WriteFile(settingsFilePath, []byte(`organizations:
+  eu-org:
+    hostname: acme.ghe.com
+`), 0o600); err != nil {
+		t.Fatalf("failed to write settings: %v", err)
+	}
+
+	output := execute(t, server, "plan", "--config", configFilePath, "--settings", settingsFilePath)
+	if !strings.Contains(output, "organization eu-org is on acme.ghe.com, but GitHub App 123 authenticates with github.com") {
+		t.Errorf("expected a conflict with the GitHub App, got:\n%s", output)
+	}
+	if appID != "" {
+		t.Errorf("expected the --app-id flag to be left unset, got %q", appID)
+	}
+}
@@ -0,0 +1,166 @@
+/*
+Copyright © 2025 Hi120ki <12624257+hi120ki@users.noreply.github.com>
+*/
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/hi120ki/gh-custom-property-manager/config"
+	"github.com/spf13/cobra"
+)
+
+var (
+	explainConfigurationFilePaths []string
+	explainLayers                 []string
+	explainVariables              variableFlags
+	explainRepositoryPolicies     config.RepositoryPolicies
+	explainPropertyName           string
+	explainFormat                 string
+)
+
+// explainCmd represents the explain command
+var explainCmd = &cobra.Command{
+	Use:   "explain ORG/REPO",
+	Short: "Explain where the desired custom property values of a repository come from",
+	Long: `Explain command lists each custom property of a repository with its desired value,
+the configuration file, line and repository entry that produced it, the values it
+overrode in other entries or layers, the values of selectors that skip the repository,
+and the current value.
+
+The explanation is written to stdout as text or JSON (--format).`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		ctx := context.Background()
+		if len(explainConfigurationFilePaths) == 0 && len(explainLayers) == 0 {
+			cmd.Println("No configuration files specified. Use --config flag to specify one or more configuration files.")
+			return
+		}
+		writeExplanation, exists := explanationWriters[explainFormat]
+		if !exists {
+			cmd.Printf("Error: unknown format %s (expected text or json)\n", explainFormat)
+			return
+		}
+
+		githubClient, err := newGitHubClient(ctx, cmd)
+		if err != nil {
+			cmd.Printf("Error creating GitHub client: %v\n", err)
+			return
+		}
+		configManager := config.NewConfig(githubClient)
+		configManager.SetProgress(progressPrinter(cmd))
+		if err := configManager.SetRepositoryPolicies(explainRepositoryPolicies); err != nil {
+			cmd.Printf("Error: %v\n", err)
+			return
+		}
+
+		if err := setVariables(configManager, explainVariables); err != nil {
+			cmd.Printf("Error: %v\n", err)
+			return
+		}
+
+		// Load all configuration files
+		if err := loadConfigurationFiles(cmd, configManager, explainConfigurationFilePaths, explainLayers); err != nil {
+			cmd.Printf("Error loading configuration: %v\n", err)
+			return
+		}
+
+		// Generate repositories
+		if err := configManager.GenerateRepositories(ctx); err != nil {
+			cmd.Printf("Error generating repositories: %v\n", err)
+			return
+		}
+
+		explanation, err := configManager.Explain(ctx, args[0], explainPropertyName)
+		if err != nil {
+			cmd.Printf("Error explaining %s: %v\n", args[0], err)
+			return
+		}
+		printWarnings(cmd, configManager)
+
+		if err := writeExplanation(cmd.OutOrStdout(), explanation); err != nil {
+			cmd.Printf("Error writing explanation: %v\n", err)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(explainCmd)
+
+	explainCmd.Flags().StringArrayVar(&explainConfigurationFilePaths, "config", []string{}, "Configuration file paths (can be specified multiple times)")
+	addLayerFlag(explainCmd, &explainLayers)
+	addVariableFlags(explainCmd, &explainVariables)
+	explainCmd.Flags().StringVar(&explainPropertyName, "property", "", "Only explain this property")
+	explainCmd.Flags().StringVar(&explainFormat, "format", "text", "Output format (text or json)")
+	addRepositoryPolicyFlags(explainCmd, &explainRepositoryPolicies)
+}
+
+// explanationWriters write an explanation in each supported format
+var explanationWriters = map[string]func(w io.Writer, explanation *config.RepositoryExplanation) error{
+	"text": writeExplanationText,
+	"json": writeExplanationJSON,
+}
+
+func writeExplanationText(w io.Writer, explanation *config.RepositoryExplanation) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s:\n", explanation.Repository)
+	if len(explanation.Properties) == 0 {
+		b.WriteString("  No custom properties set or configured.\n")
+	}
+	for _, property := range explanation.Properties {
+		fmt.Fprintf(&b, "  %s:\n", property.PropertyName)
+		switch {
+		case property.Removal:
+			fmt.Fprintf(&b, "    desired: unset by authoritative file %s\n", property.Source)
+		case property.Configured:
+			fmt.Fprintf(&b, "    desired: %s\n", property.DesiredValue)
+		default:
+			b.WriteString("    desired: not configured\n")
+		}
+		fmt.Fprintf(&b, "    current: %s%s\n", describeCurrentValue(property), explanationMarkers(property))
+		if property.Winner != nil {
+			fmt.Fprintf(&b, "    from %s\n", describeCandidate(property.Winner))
+		}
+		for _, overridden := range property.Overridden {
+			fmt.Fprintf(&b, "    overrides %s from %s\n", overridden.Value, describeCandidate(overridden))
+		}
+		for _, excluded := range property.Excluded {
+			fmt.Fprintf(&b, "    excludes %s from %s: %s\n", excluded.Value, describeCandidate(&excluded.Candidate), excluded.Reason)
+		}
+		if property.Blocked != "" {
+			fmt.Fprintf(&b, "    blocked: %s\n", property.Blocked)
+		}
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// describeCurrentValue describes the current value and whether it is up to date
+func describeCurrentValue(property *config.PropertyExplanation) string {
+	current := property.CurrentValue
+	if current == "" {
+		current = "(unset)"
+	}
+	if property.Configured {
+		if property.CurrentValue == property.DesiredValue {
+			return current + " (up to date)"
+		}
+		return current + " (differs)"
+	}
+	return current
+}
+
+// explanationMarkers returns the markers of the change to a property, like diffMarkers
+func explanationMarkers(property *config.PropertyExplanation) string {
+	return diffMarkers(&config.PropertyDiff{Markers: property.Markers, Skipped: property.Skipped})
+}
+
+func writeExplanationJSON(w io.Writer, explanation *config.RepositoryExplanation) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(explanation)
+}
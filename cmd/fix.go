@@ -0,0 +1,109 @@
+/*
+Copyright © 2025 Hi120ki <12624257+hi120ki@users.noreply.github.com>
+*/
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"os"
+
+	"github.com/hi120ki/gh-custom-property-manager/config"
+	"github.com/spf13/cobra"
+)
+
+var (
+	fixConfigurationFilePaths []string
+	fixVariables              variableFlags
+	fixRenames                bool
+	fixRecordIDs              bool
+)
+
+// fixCmd represents the fix command
+var fixCmd = &cobra.Command{
+	Use:   "fix",
+	Short: "Rewrite configuration files to follow repository renames",
+	Long: `Fix command resolves every configured repository on GitHub and rewrites the
+configuration files in place. With --renames, names of renamed or transferred
+repositories are replaced by their canonical 'org/repo' name. With --ids, the
+repository ID is recorded next to each name so that later renames are detected
+reliably.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		ctx := context.Background()
+		if len(fixConfigurationFilePaths) == 0 {
+			cmd.Println("No configuration files specified. Use --config flag to specify one or more configuration files.")
+			return
+		}
+
+		if !fixRenames && !fixRecordIDs {
+			cmd.Println("Nothing to fix. Use --renames and/or --ids flags.")
+			return
+		}
+
+		githubClient, err := newGitHubClient(ctx, cmd)
+		if err != nil {
+			cmd.Printf("Error creating GitHub client: %v\n", err)
+			return
+		}
+		configManager := config.NewConfig(githubClient)
+		configManager.SetProgress(progressPrinter(cmd))
+
+		if err := setVariables(configManager, fixVariables); err != nil {
+			cmd.Printf("Error: %v\n", err)
+			return
+		}
+
+		// Load all configuration files
+		if err := loadConfigurationFiles(cmd, configManager, fixConfigurationFilePaths, nil); err != nil {
+			cmd.Printf("Error loading configuration: %v\n", err)
+			return
+		}
+
+		// Generate repositories
+		if err := configManager.GenerateRepositories(ctx); err != nil {
+			cmd.Printf("Error generating repositories: %v\n", err)
+			return
+		}
+		printWarnings(cmd, configManager)
+		printedWarnings := len(configManager.Warnings())
+
+		for _, configFilePath := range fixConfigurationFilePaths {
+			data, err := os.ReadFile(configFilePath)
+			if err != nil {
+				cmd.Printf("Error reading config file %s: %v\n", configFilePath, err)
+				return
+			}
+
+			fixed, err := configManager.FixRepositoryNames(data, fixRenames, fixRecordIDs)
+			if err != nil {
+				cmd.Printf("Error fixing config file %s: %v\n", configFilePath, err)
+				return
+			}
+
+			if bytes.Equal(data, fixed) {
+				continue
+			}
+
+			if err := os.WriteFile(configFilePath, fixed, 0o644); err != nil {
+				cmd.Printf("Error writing config file %s: %v\n", configFilePath, err)
+				return
+			}
+			cmd.Printf("Fixed config file: %s\n", configFilePath)
+		}
+
+		// Repository entries that could not be fixed
+		for _, warning := range configManager.Warnings()[printedWarnings:] {
+			cmd.Printf("Warning: %s\n", warning)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(fixCmd)
+
+	// Add config flag that can be specified multiple times
+	fixCmd.Flags().StringArrayVar(&fixConfigurationFilePaths, "config", []string{}, "Configuration file paths (can be specified multiple times)")
+	addVariableFlags(fixCmd, &fixVariables)
+	fixCmd.Flags().BoolVar(&fixRenames, "renames", false, "Replace names of renamed or transferred repositories with their canonical name")
+	fixCmd.Flags().BoolVar(&fixRecordIDs, "ids", false, "Record repository IDs to detect future renames reliably")
+}
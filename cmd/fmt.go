@@ -0,0 +1,78 @@
+/*
+Copyright © 2025 Hi120ki <12624257+hi120ki@users.noreply.github.com>
+*/
+package cmd
+
+import (
+	"bytes"
+	"os"
+
+	"github.com/hi120ki/gh-custom-property-manager/config"
+	"github.com/spf13/cobra"
+)
+
+var (
+	fmtConfigurationFilePaths []string
+	fmtCheck                  bool
+)
+
+// fmtCmd represents the fmt command
+var fmtCmd = &cobra.Command{
+	Use:   "fmt",
+	Short: "Rewrite configuration files in canonical form",
+	Long: `Fmt command rewrites configuration files in canonical form: values are sorted,
+repositories are sorted and de-duplicated, and strings are consistently double-quoted.
+Comments are preserved.
+
+With --check, files are not modified. The command lists files that are not in
+canonical form and exits with a non-zero status if there are any.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(fmtConfigurationFilePaths) == 0 {
+			cmd.Println("No configuration files specified. Use --config flag to specify one or more configuration files.")
+			os.Exit(1)
+		}
+
+		unformatted := 0
+		for _, configFilePath := range fmtConfigurationFilePaths {
+			data, err := os.ReadFile(configFilePath)
+			if err != nil {
+				cmd.Printf("Error reading config file %s: %v\n", configFilePath, err)
+				os.Exit(1)
+			}
+
+			formatted, err := config.Format(data)
+			if err != nil {
+				cmd.Printf("Error formatting config file %s: %v\n", configFilePath, err)
+				os.Exit(1)
+			}
+
+			if bytes.Equal(data, formatted) {
+				continue
+			}
+			unformatted++
+
+			if fmtCheck {
+				cmd.Printf("Not formatted: %s\n", configFilePath)
+				continue
+			}
+
+			if err := os.WriteFile(configFilePath, formatted, 0o644); err != nil {
+				cmd.Printf("Error writing config file %s: %v\n", configFilePath, err)
+				os.Exit(1)
+			}
+			cmd.Printf("Formatted config file: %s\n", configFilePath)
+		}
+
+		if fmtCheck && unformatted > 0 {
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(fmtCmd)
+
+	// Add config flag that can be specified multiple times
+	fmtCmd.Flags().StringArrayVar(&fmtConfigurationFilePaths, "config", []string{}, "Configuration file paths (can be specified multiple times)")
+	fmtCmd.Flags().BoolVar(&fmtCheck, "check", false, "Check whether files are formatted without modifying them")
+}
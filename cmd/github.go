@@ -0,0 +1,320 @@
+/*
+Copyright © 2025 Hi120ki <12624257+hi120ki@users.noreply.github.com>
+*/
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hi120ki/gh-custom-property-manager/client"
+	"github.com/hi120ki/gh-custom-property-manager/config"
+	"github.com/hi120ki/gh-custom-property-manager/credential"
+	"github.com/hi120ki/gh-custom-property-manager/settings"
+	"github.com/spf13/cobra"
+)
+
+var (
+	appID             string
+	appPrivateKeyFile string
+	tokenFile         string
+	credentialCommand string
+	hostname          string
+	baseURL           string
+	caBundleFile      string
+	proxyURL          string
+	settingsFilePath  string
+	noCache           bool
+	cacheDir          string
+	cacheTTL          time.Duration
+	recordDir         string
+	replayDir         string
+)
+
+// addGitHubFlags adds the flags selecting the GitHub instance and credentials
+func addGitHubFlags(command *cobra.Command) {
+	// Add GitHub App authentication flags
+	command.PersistentFlags().StringVar(&appID, "app-id", "", "GitHub App ID to authenticate as (default $GITHUB_APP_ID)")
+	command.PersistentFlags().StringVar(&appPrivateKeyFile, "app-private-key-file", "", "Path to the GitHub App private key (default $GITHUB_APP_PRIVATE_KEY_FILE)")
+
+	// Add credential flags, tried before GH_TOKEN/GITHUB_TOKEN and the gh CLI
+	command.PersistentFlags().StringVar(&tokenFile, "token-file", "", "Path to a file containing the GitHub token")
+	command.PersistentFlags().StringVar(&credentialCommand, "credential-command", "", "Command printing the GitHub token (the host is passed in $GITHUB_HOST)")
+
+	// Add GitHub instance flags
+	command.PersistentFlags().StringVar(&hostname, "hostname", "", "GitHub hostname, e.g. a GitHub Enterprise Server or GHE.com host (default $GH_HOST or github.com)")
+	command.PersistentFlags().StringVar(&baseURL, "base-url", "", "REST API base URL, overriding the one derived from --hostname")
+	command.PersistentFlags().StringVar(&caBundleFile, "ca-bundle", "", "Path to a PEM file with additional trusted certificate authorities")
+	command.PersistentFlags().StringVar(&proxyURL, "proxy", "", "HTTPS proxy URL (default $HTTPS_PROXY)")
+	command.PersistentFlags().StringVar(&settingsFilePath, "settings", "", "Path to the tool settings file with per-organization hosts and credentials")
+
+	// Add response cache flags
+	command.PersistentFlags().BoolVar(&noCache, "no-cache", false, "Do not cache GitHub API responses on disk")
+	command.PersistentFlags().StringVar(&cacheDir, "cache-dir", "", "Directory of the GitHub API response cache (default is the user cache directory)")
+	command.PersistentFlags().DurationVar(&cacheTTL, "cache-ttl", client.DefaultCacheTTL, "How long cached GitHub API responses are kept")
+
+	// Add record/replay flags for reproducing bug reports
+	command.PersistentFlags().StringVar(&recordDir, "record", "", "Directory to record sanitized GitHub API traffic to")
+	command.PersistentFlags().StringVar(&replayDir, "replay", "", "Directory of recorded GitHub API traffic to replay instead of contacting GitHub")
+}
+
+// checkRecordDir makes sure recordings of different runs are not mixed up
+func checkRecordDir() error {
+	if recordDir == "" {
+		return nil
+	}
+	if replayDir != "" {
+		return fmt.Errorf("--record and --replay cannot be used together")
+	}
+	entries, err := os.ReadDir(recordDir)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to read record directory %s: %w", recordDir, err)
+	}
+	if len(entries) > 0 {
+		return fmt.Errorf("record directory %s is not empty", recordDir)
+	}
+	return nil
+}
+
+// newCache returns the on-disk response cache, or nil when caching is disabled
+func newCache() (*client.Cache, error) {
+	if noCache {
+		return nil, nil
+	}
+	dir := cacheDir
+	if dir == "" {
+		var err error
+		dir, err = client.DefaultCacheDir()
+		if err != nil {
+			return nil, err
+		}
+	}
+	return client.NewCache(dir, cacheTTL), nil
+}
+
+// loadSettings reads the tool settings file, if any, and applies command line overrides
+func loadSettings() (*settings.Settings, error) {
+	toolSettings := &settings.Settings{}
+	if settingsFilePath != "" {
+		settingsFile, err := os.Open(settingsFilePath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open settings file %s: %w", settingsFilePath, err)
+		}
+		defer settingsFile.Close()
+
+		toolSettings, err = settings.Load(settingsFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load settings from %s: %w", settingsFilePath, err)
+		}
+	}
+
+	if hostname != "" {
+		toolSettings.Hostname = hostname
+	}
+	if toolSettings.Hostname == "" {
+		toolSettings.Hostname = os.Getenv("GH_HOST")
+	}
+	if toolSettings.Hostname == "" {
+		toolSettings.Hostname = credential.DefaultHost
+	}
+	if caBundleFile != "" {
+		toolSettings.CABundle = caBundleFile
+	}
+	if proxyURL != "" {
+		toolSettings.Proxy = proxyURL
+	}
+	return toolSettings, nil
+}
+
+// newGitHubClient creates the GitHub client for all organizations. Organizations with
+// their own host or credentials in the settings file are routed to a dedicated client.
+func newGitHubClient(ctx context.Context, cmd *cobra.Command) (config.GitHubClient, error) {
+	toolSettings, err := loadSettings()
+	if err != nil {
+		return nil, err
+	}
+
+	// The GitHub App is not needed to replay recorded responses
+	var app *appCredentials
+	if replayDir == "" {
+		if app, err = loadAppCredentials(); err != nil {
+			return nil, err
+		}
+		if err := checkAppSettings(app, toolSettings); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := checkRecordDir(); err != nil {
+		return nil, err
+	}
+	cache, err := newCache()
+	if err != nil {
+		return nil, err
+	}
+
+	type clientKey struct {
+		host        string
+		credentials settings.Credentials
+	}
+	clients := make(map[clientKey]config.GitHubClient)
+	clientFor := func(org string) (config.GitHubClient, error) {
+		key := clientKey{
+			host:        strings.ToLower(toolSettings.HostnameFor(org)),
+			credentials: toolSettings.CredentialsFor(org),
+		}
+		if githubClient, exists := clients[key]; exists {
+			return githubClient, nil
+		}
+		githubClient, err := newHostClient(ctx, cmd, toolSettings.HostnameFor(org), key.credentials, app, toolSettings, cache)
+		if err != nil {
+			return nil, err
+		}
+		clients[key] = githubClient
+		return githubClient, nil
+	}
+
+	defaultClient, err := clientFor("")
+	if err != nil {
+		return nil, err
+	}
+
+	router := config.NewRouter(defaultClient)
+	for org := range toolSettings.Organizations {
+		organizationClient, err := clientFor(org)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create client for organization %s: %w", org, err)
+		}
+		router.Route(org, organizationClient)
+	}
+	return router, nil
+}
+
+// newHostClient creates a client for a GitHub host. Credentials from the settings file
+// take precedence; otherwise it authenticates as the GitHub App, if any, and with a
+// token from the credential chain as a last resort.
+func newHostClient(ctx context.Context, cmd *cobra.Command, host string, credentials settings.Credentials, app *appCredentials, toolSettings *settings.Settings, cache *client.Cache) (*client.Client, error) {
+	options := client.Options{
+		Hostname:     host,
+		CABundleFile: toolSettings.CABundle,
+		Proxy:        toolSettings.Proxy,
+		Cache:        cache,
+		RecordDir:    recordDir,
+		ReplayDir:    replayDir,
+	}
+	if strings.EqualFold(host, toolSettings.Hostname) {
+		options.BaseURL = baseURL
+	}
+
+	if replayDir != "" {
+		// Recorded fixtures carry no credentials, so none are needed to replay them
+		cmd.Printf("Replaying recorded responses for %s from %s\n", host, replayDir)
+		return client.NewClientWithOptions(ctx, "replay", options)
+	}
+
+	if app != nil && credentials.IsZero() {
+		cmd.Printf("Using credentials for %s from GitHub App %d\n", host, app.id)
+		return client.NewAppClient(ctx, app.id, app.privateKey, options)
+	}
+
+	githubCredential, err := credentialChain(credentials).Resolve(ctx, host)
+	if err != nil {
+		return nil, err
+	}
+	cmd.Printf("Using credentials for %s from %s\n", host, githubCredential.Redacted())
+	return client.NewClientWithOptions(ctx, githubCredential.Token, options)
+}
+
+// credentialChain returns the credential providers in order of precedence. Credentials
+// from the settings file are used exclusively. Otherwise explicitly configured sources
+// come first, then environment variables, then the gh CLI.
+func credentialChain(credentials settings.Credentials) *credential.Chain {
+	if !credentials.IsZero() {
+		var providers []credential.Provider
+		if credentials.TokenEnv != "" {
+			providers = append(providers, &credential.VariableProvider{Variable: credentials.TokenEnv})
+		}
+		if credentials.TokenFile != "" {
+			providers = append(providers, &credential.FileProvider{Path: credentials.TokenFile})
+		}
+		if credentials.CredentialCommand != "" {
+			providers = append(providers, &credential.CommandProvider{Command: credentials.CredentialCommand})
+		}
+		return credential.NewChain(providers...)
+	}
+
+	var providers []credential.Provider
+	if tokenFile != "" {
+		providers = append(providers, &credential.FileProvider{Path: tokenFile})
+	}
+	if credentialCommand != "" {
+		providers = append(providers, &credential.CommandProvider{Command: credentialCommand})
+	}
+	providers = append(providers, credential.NewEnvProvider(), credential.NewGhCLIProvider())
+	return credential.NewChain(providers...)
+}
+
+// appCredentials are the ID and private key of the GitHub App to authenticate as
+type appCredentials struct {
+	id         int64
+	privateKey []byte
+}
+
+// loadAppCredentials reads the GitHub App credentials from the flags and environment.
+// It returns nil when no app ID is configured.
+func loadAppCredentials() (*appCredentials, error) {
+	id := appID
+	if id == "" {
+		id = os.Getenv("GITHUB_APP_ID")
+	}
+	if id == "" {
+		return nil, nil
+	}
+	parsedID, err := strconv.ParseInt(id, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid GitHub App ID %s: %w", id, err)
+	}
+
+	privateKeyFile := appPrivateKeyFile
+	if privateKeyFile == "" {
+		privateKeyFile = os.Getenv("GITHUB_APP_PRIVATE_KEY_FILE")
+	}
+	var privateKey []byte
+	if privateKeyFile != "" {
+		privateKey, err = os.ReadFile(privateKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read GitHub App private key: %w", err)
+		}
+	} else {
+		privateKey = []byte(os.Getenv("GITHUB_APP_PRIVATE_KEY"))
+	}
+	if len(privateKey) == 0 {
+		return nil, fmt.Errorf("GitHub App private key is not set; use --app-private-key-file or GITHUB_APP_PRIVATE_KEY")
+	}
+
+	return &appCredentials{id: parsedID, privateKey: privateKey}, nil
+}
+
+// checkAppSettings rejects settings that conflict with GitHub App authentication.
+// Organizations with their own credentials keep using them, but the app is registered
+// on a single host, so organizations on other hosts need credentials of their own.
+func checkAppSettings(app *appCredentials, toolSettings *settings.Settings) error {
+	if app == nil {
+		return nil
+	}
+	if !toolSettings.Credentials.IsZero() {
+		return fmt.Errorf("default credentials in the settings file cannot be combined with GitHub App authentication")
+	}
+	for org, organizationSettings := range toolSettings.Organizations {
+		host := toolSettings.HostnameFor(org)
+		if organizationSettings.Credentials.IsZero() && !strings.EqualFold(host, toolSettings.Hostname) {
+			return fmt.Errorf("organization %s is on %s, but GitHub App %d authenticates with %s; set credentials for the organization in the settings file",
+				org, host, app.id, toolSettings.Hostname)
+		}
+	}
+	return nil
+}
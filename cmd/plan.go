@@ -5,14 +5,25 @@ package cmd
 
 import (
 	"context"
+	"fmt"
 	"os"
+	"time"
 
-	"github.com/hi120ki/gh-custom-property-manager/client"
 	"github.com/hi120ki/gh-custom-property-manager/config"
+	"github.com/hi120ki/gh-custom-property-manager/state"
 	"github.com/spf13/cobra"
 )
 
-var planConfigurationFilePaths []string
+var (
+	planConfigurationFilePaths []string
+	planLayers                 []string
+	planVariables              variableFlags
+	planRepositoryPolicies     config.RepositoryPolicies
+	planMaxRemovals            int
+	planStateFilePath          string
+	planOutputFilePath         string
+	planExplain                bool
+)
 
 // planCmd represents the plan command
 var planCmd = &cobra.Command{
@@ -23,34 +34,46 @@ based on the configuration files. It compares the current state with the desired
 defined in the configuration files and displays the differences.`,
 	Run: func(cmd *cobra.Command, args []string) {
 		ctx := context.Background()
-		githubToken := os.Getenv("GITHUB_TOKEN")
-		if githubToken == "" {
-			cmd.Println("GITHUB_TOKEN environment variable is not set.")
-			return
-		}
-
-		if len(planConfigurationFilePaths) == 0 {
+		if len(planConfigurationFilePaths) == 0 && len(planLayers) == 0 {
 			cmd.Println("No configuration files specified. Use --config flag to specify one or more configuration files.")
 			return
 		}
 
-		githubClient := client.NewClient(ctx, githubToken)
-		configManager := config.NewConfig(githubClient)
-
-		// Load all configuration files
-		for _, configFilePath := range planConfigurationFilePaths {
-			configFile, err := os.Open(configFilePath)
+		var githubClient config.GitHubClient
+		if planStateFilePath != "" {
+			// Plan offline against a snapshot, without credentials
+			snapshot, err := loadSnapshot(planStateFilePath)
 			if err != nil {
-				cmd.Printf("Error opening config file %s: %v\n", configFilePath, err)
+				cmd.Printf("Error loading state: %v\n", err)
 				return
 			}
-			defer configFile.Close()
-
-			if err := configManager.LoadConfig(configFile); err != nil {
-				cmd.Printf("Error loading config from %s: %v\n", configFilePath, err)
+			cmd.Printf("Planning against state snapshot %s taken at %s\n", planStateFilePath, snapshot.CreatedAt.Format(time.RFC3339))
+			githubClient = state.NewClient(snapshot)
+		} else {
+			var err error
+			githubClient, err = newGitHubClient(ctx, cmd)
+			if err != nil {
+				cmd.Printf("Error creating GitHub client: %v\n", err)
 				return
 			}
-			cmd.Printf("Loaded config file: %s\n", configFilePath)
+		}
+		configManager := config.NewConfig(githubClient)
+		configManager.SetProgress(progressPrinter(cmd))
+		if err := configManager.SetRepositoryPolicies(planRepositoryPolicies); err != nil {
+			cmd.Printf("Error: %v\n", err)
+			return
+		}
+		configManager.SetMaxRemovals(planMaxRemovals)
+
+		if err := setVariables(configManager, planVariables); err != nil {
+			cmd.Printf("Error: %v\n", err)
+			return
+		}
+
+		// Load all configuration files
+		if err := loadConfigurationFiles(cmd, configManager, planConfigurationFilePaths, planLayers); err != nil {
+			cmd.Printf("Error loading configuration: %v\n", err)
+			return
 		}
 
 		// Generate repositories
@@ -65,20 +88,22 @@ defined in the configuration files and displays the differences.`,
 			cmd.Printf("Error generating diffs: %v\n", err)
 			return
 		}
+		printWarnings(cmd, configManager)
+
+		if planOutputFilePath != "" {
+			if err := writePlan(planOutputFilePath, propertyDiffs); err != nil {
+				cmd.Printf("Error saving plan: %v\n", err)
+				return
+			}
+			cmd.Printf("Saved plan to %s\n", planOutputFilePath)
+		}
 
 		if len(propertyDiffs) == 0 {
 			cmd.Println("No changes needed.")
 			return
 		}
 
-		cmd.Println("Planned changes:")
-		for _, diff := range propertyDiffs {
-			if diff.OldValue == "" {
-				cmd.Printf("  %s/%s: Set %s = %s\n", diff.Organization, diff.Repository, diff.PropertyName, diff.NewValue)
-			} else {
-				cmd.Printf("  %s/%s: Change %s from %s to %s\n", diff.Organization, diff.Repository, diff.PropertyName, diff.OldValue, diff.NewValue)
-			}
-		}
+		printPlan(cmd, propertyDiffs, planExplain)
 	},
 }
 
@@ -87,4 +112,24 @@ func init() {
 
 	// Add config flag that can be specified multiple times
 	planCmd.Flags().StringArrayVar(&planConfigurationFilePaths, "config", []string{}, "Configuration file paths (can be specified multiple times)")
+	addLayerFlag(planCmd, &planLayers)
+	addVariableFlags(planCmd, &planVariables)
+	planCmd.Flags().StringVar(&planStateFilePath, "state", "", "Plan offline against a state snapshot written by 'state pull'")
+	planCmd.Flags().StringVarP(&planOutputFilePath, "out", "o", "", "Save the planned changes as JSON to this file, e.g. for a later rollback")
+	planCmd.Flags().BoolVar(&planExplain, "explain", false, "Show which configuration file, layer and repository entry each planned value comes from")
+	addRepositoryPolicyFlags(planCmd, &planRepositoryPolicies)
+	addMaxRemovalsFlag(planCmd, &planMaxRemovals)
+}
+
+// writePlan saves the planned changes to a file
+func writePlan(path string, propertyDiffs []*config.PropertyDiff) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create %s: %w", path, err)
+	}
+	if err := config.WritePlan(file, propertyDiffs, time.Now()); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
 }
@@ -0,0 +1,108 @@
+/*
+Copyright © 2025 Hi120ki <12624257+hi120ki@users.noreply.github.com>
+*/
+package cmd
+
+import (
+	"context"
+	"time"
+
+	"github.com/hi120ki/gh-custom-property-manager/config"
+	"github.com/spf13/cobra"
+)
+
+var (
+	restoreDryRun             bool
+	restoreAuditLogFilePath   string
+	restoreRepositoryPolicies config.RepositoryPolicies
+)
+
+// restoreCmd represents the restore command
+var restoreCmd = &cobra.Command{
+	Use:   "restore BACKUP",
+	Short: "Restore custom property values from a backup",
+	Long: `Restore command compares the repositories with a file written by the backup
+command and applies the changes needed to go back to the backed up values. Properties
+missing from a repository in the backup are unset. Use --dry-run to only show the plan.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		ctx := context.Background()
+
+		snapshot, err := loadSnapshot(args[0])
+		if err != nil {
+			cmd.Printf("Error loading backup: %v\n", err)
+			return
+		}
+		cmd.Printf("Restoring backup %s taken at %s\n", args[0], snapshot.CreatedAt.Format(time.RFC3339))
+
+		githubClient, err := newGitHubClient(ctx, cmd)
+		if err != nil {
+			cmd.Printf("Error creating GitHub client: %v\n", err)
+			return
+		}
+		configManager := config.NewConfig(githubClient)
+		configManager.SetProgress(progressPrinter(cmd))
+		if err := configManager.SetRepositoryPolicies(restoreRepositoryPolicies); err != nil {
+			cmd.Printf("Error: %v\n", err)
+			return
+		}
+		auditLog, err := openAuditLog(cmd, configManager, githubClient, restoreAuditLogFilePath)
+		if err != nil {
+			cmd.Printf("Error: %v\n", err)
+			return
+		}
+		if auditLog != nil {
+			defer auditLog.Close()
+		}
+
+		configFiles, skipped := snapshot.ConfigFiles()
+		for _, configFile := range configFiles {
+			if err := configManager.AddConfigFile(configFile); err != nil {
+				cmd.Printf("Error loading backup: %v\n", err)
+				return
+			}
+		}
+		for _, value := range skipped {
+			cmd.Printf("Warning: cannot restore non-string value of %s\n", value)
+		}
+
+		// Generate repositories
+		if err := configManager.GenerateRepositories(ctx); err != nil {
+			cmd.Printf("Error generating repositories: %v\n", err)
+			return
+		}
+
+		// Generate diffs
+		propertyDiffs, err := configManager.GenerateDiffs(ctx)
+		if err != nil {
+			cmd.Printf("Error generating diffs: %v\n", err)
+			return
+		}
+		printWarnings(cmd, configManager)
+
+		if len(propertyDiffs) == 0 {
+			cmd.Println("No changes needed.")
+			return
+		}
+
+		if restoreDryRun {
+			printPlan(cmd, propertyDiffs, false)
+			return
+		}
+
+		if err := applyDiffs(ctx, cmd, configManager, propertyDiffs); err != nil {
+			cmd.Printf("Error applying change: %v\n", err)
+			return
+		}
+
+		cmd.Println("All changes applied successfully.")
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(restoreCmd)
+
+	restoreCmd.Flags().BoolVar(&restoreDryRun, "dry-run", false, "Only show the changes restoring the backup")
+	addRepositoryPolicyFlags(restoreCmd, &restoreRepositoryPolicies)
+	addAuditLogFlag(restoreCmd, &restoreAuditLogFilePath)
+}
@@ -0,0 +1,179 @@
+/*
+Copyright © 2025 Hi120ki <12624257+hi120ki@users.noreply.github.com>
+*/
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+
+	"github.com/hi120ki/gh-custom-property-manager/audit"
+	"github.com/hi120ki/gh-custom-property-manager/config"
+	"github.com/spf13/cobra"
+)
+
+var (
+	rollbackDryRun             bool
+	rollbackAuditLogFilePath   string
+	rollbackRepositoryPolicies config.RepositoryPolicies
+)
+
+// rollbackCmd represents the rollback command
+var rollbackCmd = &cobra.Command{
+	Use:   "rollback RUN_ID|FILE",
+	Short: "Revert the changes of a previous apply",
+	Long: `Rollback command reverts the changes made by a previous run. The changes are taken
+from the records of a run ID in the audit log given with --audit-log, or from a file:
+either a plan saved with 'plan --out' or audit log records. The old values are set
+again, except on repositories whose value was changed since, which are skipped with
+a warning. The rollback itself is recorded in the audit log under a new run ID.
+Use --dry-run to only show the plan.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		ctx := context.Background()
+
+		appliedDiffs, err := loadAppliedChanges(args[0], rollbackAuditLogFilePath)
+		if err != nil {
+			cmd.Printf("Error loading changes: %v\n", err)
+			return
+		}
+		rollback := config.NewRollback(appliedDiffs)
+		configFiles := rollback.ConfigFiles()
+		if len(configFiles) == 0 {
+			cmd.Println("No changes to roll back.")
+			return
+		}
+
+		githubClient, err := newGitHubClient(ctx, cmd)
+		if err != nil {
+			cmd.Printf("Error creating GitHub client: %v\n", err)
+			return
+		}
+		configManager := config.NewConfig(githubClient)
+		configManager.SetProgress(progressPrinter(cmd))
+		if err := configManager.SetRepositoryPolicies(rollbackRepositoryPolicies); err != nil {
+			cmd.Printf("Error: %v\n", err)
+			return
+		}
+		for _, configFile := range configFiles {
+			if err := configManager.AddConfigFile(configFile); err != nil {
+				cmd.Printf("Error loading changes: %v\n", err)
+				return
+			}
+		}
+
+		// Generate repositories
+		if err := configManager.GenerateRepositories(ctx); err != nil {
+			cmd.Printf("Error generating repositories: %v\n", err)
+			return
+		}
+
+		// Generate diffs
+		propertyDiffs, err := configManager.GenerateDiffs(ctx)
+		if err != nil {
+			cmd.Printf("Error generating diffs: %v\n", err)
+			return
+		}
+		printWarnings(cmd, configManager)
+
+		propertyDiffs, changed := rollback.Filter(propertyDiffs, configManager.Renames())
+		for _, warning := range changed {
+			cmd.Printf("Warning: %s\n", warning)
+		}
+
+		if len(propertyDiffs) == 0 {
+			cmd.Println("No changes needed.")
+			return
+		}
+
+		printPlan(cmd, propertyDiffs, false)
+		if rollbackDryRun {
+			return
+		}
+
+		auditLog, err := openAuditLog(cmd, configManager, githubClient, rollbackAuditLogFilePath)
+		if err != nil {
+			cmd.Printf("Error: %v\n", err)
+			return
+		}
+		if auditLog != nil {
+			defer auditLog.Close()
+		}
+
+		if err := applyDiffs(ctx, cmd, configManager, propertyDiffs); err != nil {
+			cmd.Printf("Error applying change: %v\n", err)
+			return
+		}
+
+		cmd.Println("All changes rolled back successfully.")
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(rollbackCmd)
+
+	rollbackCmd.Flags().BoolVar(&rollbackDryRun, "dry-run", false, "Only show the changes reverting the run")
+	addRepositoryPolicyFlags(rollbackCmd, &rollbackRepositoryPolicies)
+	addAuditLogFlag(rollbackCmd, &rollbackAuditLogFilePath)
+}
+
+// loadAppliedChanges returns the successfully applied changes of a run, in the order
+// they were applied. The argument is a saved plan or audit log file, or a run ID
+// looked up in the audit log.
+func loadAppliedChanges(runIDOrFile, auditLogFilePath string) ([]*config.PropertyDiff, error) {
+	data, err := os.ReadFile(runIDOrFile)
+	if err == nil {
+		if plan, err := config.ReadPlan(bytes.NewReader(data)); err == nil {
+			return plan.Changes, nil
+		}
+		records, err := audit.Read(bytes.NewReader(data))
+		if err != nil {
+			return nil, fmt.Errorf("%s is neither a saved plan nor an audit log: %w", runIDOrFile, err)
+		}
+		return appliedChanges(records, ""), nil
+	}
+	if !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+
+	if auditLogFilePath == "" {
+		return nil, fmt.Errorf("%s is not a file; use --audit-log to look up a run ID", runIDOrFile)
+	}
+	file, err := os.Open(auditLogFilePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open audit log %s: %w", auditLogFilePath, err)
+	}
+	defer file.Close()
+	records, err := audit.Read(file)
+	if err != nil {
+		return nil, err
+	}
+	changes := appliedChanges(records, runIDOrFile)
+	if len(changes) == 0 {
+		return nil, fmt.Errorf("no applied changes of run %s found in %s", runIDOrFile, auditLogFilePath)
+	}
+	return changes, nil
+}
+
+// appliedChanges converts the successful records of a run to property diffs. An empty
+// run ID selects all records.
+func appliedChanges(records []*audit.Record, runID string) []*config.PropertyDiff {
+	var changes []*config.PropertyDiff
+	for _, record := range records {
+		if record.Result != audit.ResultSuccess || (runID != "" && record.RunID != runID) {
+			continue
+		}
+		changes = append(changes, &config.PropertyDiff{
+			Organization: record.Organization,
+			Repository:   record.Repository,
+			PropertyName: record.Property,
+			OldValue:     record.OldValue,
+			NewValue:     record.NewValue,
+		})
+	}
+	return changes
+}
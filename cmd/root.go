@@ -4,9 +4,15 @@ Copyright © 2025 Hi120ki <12624257+hi120ki@users.noreply.github.com>
 package cmd
 
 import (
+	"context"
 	"fmt"
 	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
 
+	"github.com/hi120ki/gh-custom-property-manager/config"
 	"github.com/spf13/cobra"
 )
 
@@ -48,6 +54,212 @@ func Execute() {
 	}
 }
 
+// loadConfigurationFiles loads each configuration file into the config manager,
+// followed by the layers given as PRIORITY=PATH. Paths may be directories, whose
+// YAML files are loaded in name order.
+func loadConfigurationFiles(cmd *cobra.Command, configManager *config.Config, configFilePaths []string, layers []string) error {
+	for _, configFilePath := range configFilePaths {
+		if err := loadConfigurationPath(cmd, configManager, configFilePath, 0); err != nil {
+			return err
+		}
+	}
+	for _, layer := range layers {
+		priority, path, found := strings.Cut(layer, "=")
+		value, err := strconv.Atoi(priority)
+		if !found || err != nil || path == "" {
+			return fmt.Errorf("layer %s is not in the format PRIORITY=PATH", layer)
+		}
+		if err := loadConfigurationPath(cmd, configManager, path, value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// loadConfigurationPath loads a configuration file, or the YAML files of a directory,
+// as a layer with the given priority
+func loadConfigurationPath(cmd *cobra.Command, configManager *config.Config, path string, priority int) error {
+	configFilePaths := []string{path}
+	if info, err := os.Stat(path); err == nil && info.IsDir() {
+		configFilePaths = nil
+		for _, pattern := range []string{"*.yaml", "*.yml"} {
+			matches, err := filepath.Glob(filepath.Join(path, pattern))
+			if err != nil {
+				return err
+			}
+			configFilePaths = append(configFilePaths, matches...)
+		}
+		if len(configFilePaths) == 0 {
+			return fmt.Errorf("no configuration files found in %s", path)
+		}
+		sort.Strings(configFilePaths)
+	}
+
+	for _, configFilePath := range configFilePaths {
+		configFile, err := os.Open(configFilePath)
+		if err != nil {
+			return fmt.Errorf("failed to open config file %s: %w", configFilePath, err)
+		}
+
+		err = configManager.LoadLayer(configFile, priority)
+		configFile.Close()
+		if err != nil {
+			return fmt.Errorf("failed to load config from %s: %w", configFilePath, err)
+		}
+		if priority != 0 {
+			cmd.Printf("Loaded config file: %s (priority %d)\n", configFilePath, priority)
+		} else {
+			cmd.Printf("Loaded config file: %s\n", configFilePath)
+		}
+	}
+	return nil
+}
+
+// addLayerFlag adds the flag loading configuration files as prioritized layers
+func addLayerFlag(command *cobra.Command, layers *[]string) {
+	command.Flags().StringArrayVar(layers, "layer", []string{}, "Load a configuration file or directory as a layer overriding lower priorities, as PRIORITY=PATH (can be specified multiple times)")
+}
+
+// variableFlags are the flags setting variables interpolated into configuration files
+type variableFlags struct {
+	variables []string
+	files     []string
+}
+
+// addVariableFlags adds the flags setting variables interpolated into configuration files
+func addVariableFlags(command *cobra.Command, flags *variableFlags) {
+	command.Flags().StringArrayVar(&flags.variables, "var", []string{}, "Set a variable referenced as ${NAME} in configuration files, as NAME=VALUE (can be specified multiple times)")
+	command.Flags().StringArrayVar(&flags.files, "var-file", []string{}, "Read variables from a YAML file mapping names to values (can be specified multiple times)")
+}
+
+// setVariables passes the variables of the flags to the config manager. Later files
+// override earlier ones, and --var overrides all files.
+func setVariables(configManager *config.Config, flags variableFlags) error {
+	variables := make(map[string]string)
+	for _, path := range flags.files {
+		file, err := os.Open(path)
+		if err != nil {
+			return fmt.Errorf("failed to open variable file %s: %w", path, err)
+		}
+		fileVariables, err := config.ReadVariables(file)
+		file.Close()
+		if err != nil {
+			return fmt.Errorf("failed to load variables from %s: %w", path, err)
+		}
+		for name, value := range fileVariables {
+			variables[name] = value
+		}
+	}
+	for _, variable := range flags.variables {
+		name, value, found := strings.Cut(variable, "=")
+		if !found || name == "" {
+			return fmt.Errorf("variable %s is not in the format NAME=VALUE", variable)
+		}
+		variables[name] = value
+	}
+	configManager.SetVariables(variables)
+	return nil
+}
+
+// printWarnings prints non-fatal problems reported by the config manager
+func printWarnings(cmd *cobra.Command, configManager *config.Config) {
+	for _, warning := range configManager.Warnings() {
+		cmd.Printf("Warning: %s\n", warning)
+	}
+}
+
+// progressPrinter returns a function printing progress messages to stderr, so that
+// long enumerations of organizations are visible without cluttering the plan output
+func progressPrinter(cmd *cobra.Command) func(message string) {
+	return func(message string) {
+		cmd.PrintErrln(message)
+	}
+}
+
+// addRepositoryPolicyFlags adds flags for handling archived, disabled and template repositories
+func addRepositoryPolicyFlags(command *cobra.Command, policies *config.RepositoryPolicies) {
+	*policies = config.DefaultRepositoryPolicies()
+	command.Flags().StringVar((*string)(&policies.Archived), "archived", string(policies.Archived), "Policy for archived repositories (skip, warn or error)")
+	command.Flags().StringVar((*string)(&policies.Disabled), "disabled", string(policies.Disabled), "Policy for disabled repositories (skip, warn or error)")
+	command.Flags().StringVar((*string)(&policies.Template), "template", string(policies.Template), "Policy for template repositories (skip, warn or error)")
+}
+
+// addMaxRemovalsFlag adds the flag limiting removals by authoritative configuration files
+func addMaxRemovalsFlag(command *cobra.Command, maxRemovals *int) {
+	command.Flags().IntVar(maxRemovals, "max-removals", config.DefaultMaxRemovals, "Abort if authoritative configuration files would remove more values than this (-1 for no limit)")
+}
+
+// diffMarkers formats the markers of a property diff for display
+func diffMarkers(diff *config.PropertyDiff) string {
+	markers := diff.Markers
+	if diff.Skipped {
+		markers = append(markers[:len(markers):len(markers)], "skipped")
+	}
+	if len(markers) == 0 {
+		return ""
+	}
+	return fmt.Sprintf(" [%s]", strings.Join(markers, ", "))
+}
+
+// printPlan prints the changes that would be applied
+func printPlan(cmd *cobra.Command, propertyDiffs []*config.PropertyDiff, explain bool) {
+	cmd.Println("Planned changes:")
+	for _, diff := range propertyDiffs {
+		switch {
+		case diff.NewValue == "":
+			cmd.Printf("  %s/%s: Unset %s (was %s)%s\n", diff.Organization, diff.Repository, diff.PropertyName, diff.OldValue, diffMarkers(diff))
+		case diff.OldValue == "":
+			cmd.Printf("  %s/%s: Set %s = %s%s\n", diff.Organization, diff.Repository, diff.PropertyName, diff.NewValue, diffMarkers(diff))
+		default:
+			cmd.Printf("  %s/%s: Change %s from %s to %s%s\n", diff.Organization, diff.Repository, diff.PropertyName, diff.OldValue, diff.NewValue, diffMarkers(diff))
+		}
+		if explain && diff.Explanation != nil {
+			printExplanation(cmd, diff.Explanation)
+		}
+	}
+}
+
+// printExplanation prints the configured value that won and the ones it overrode
+func printExplanation(cmd *cobra.Command, explanation *config.Explanation) {
+	cmd.Printf("      from %s\n", describeCandidate(explanation.Winner))
+	for _, overridden := range explanation.Overridden {
+		cmd.Printf("      overrides %s from %s\n", overridden.Value, describeCandidate(overridden))
+	}
+}
+
+// describeCandidate describes where a configured value comes from
+func describeCandidate(candidate *config.Candidate) string {
+	location := candidate.Source
+	if location == "" {
+		location = "<unknown>"
+	}
+	if candidate.Line > 0 {
+		location = fmt.Sprintf("%s:%d", location, candidate.Line)
+	}
+	return fmt.Sprintf("%s (priority %d, %s)", location, candidate.Priority, candidate.Entry)
+}
+
+// applyDiffs applies the changes one by one and stops at the first failure
+func applyDiffs(ctx context.Context, cmd *cobra.Command, configManager *config.Config, propertyDiffs []*config.PropertyDiff) error {
+	cmd.Println("Applying changes:")
+
+	for _, diff := range propertyDiffs {
+		switch {
+		case diff.Skipped:
+			cmd.Printf("  %s/%s: Skip %s = %s%s\n", diff.Organization, diff.Repository, diff.PropertyName, diff.NewValue, diffMarkers(diff))
+		case diff.NewValue == "":
+			cmd.Printf("  %s/%s: Unset %s%s\n", diff.Organization, diff.Repository, diff.PropertyName, diffMarkers(diff))
+		default:
+			cmd.Printf("  %s/%s: Set %s = %s%s\n", diff.Organization, diff.Repository, diff.PropertyName, diff.NewValue, diffMarkers(diff))
+		}
+		// Skipped changes are passed on too, so that they are recorded in the audit log
+		if err := configManager.ApplyChange(ctx, diff); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func init() {
 	// Here you will define your flags and configuration settings.
 	// Cobra supports persistent flags, which, if defined here,
@@ -59,6 +271,8 @@ func init() {
 	// when this action is called directly.
 	rootCmd.Flags().BoolP("toggle", "t", false, "Help message for toggle")
 
+	addGitHubFlags(rootCmd)
+
 	// Add version flag
 	rootCmd.Version = fmt.Sprintf("%s (commit: %s, built at: %s)", version, commit, date)
 }
@@ -0,0 +1,117 @@
+/*
+Copyright © 2025 Hi120ki <12624257+hi120ki@users.noreply.github.com>
+*/
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/hi120ki/gh-custom-property-manager/config"
+	"github.com/hi120ki/gh-custom-property-manager/state"
+	"github.com/spf13/cobra"
+)
+
+var (
+	statePullConfigurationFilePaths []string
+	statePullLayers                 []string
+	statePullVariables              variableFlags
+	statePullOutputFilePath         string
+)
+
+// stateCmd represents the state command
+var stateCmd = &cobra.Command{
+	Use:   "state",
+	Short: "Manage snapshots of custom property values",
+	Long: `State commands save the current custom property values of the configured
+repositories to a snapshot. Snapshots can be planned against offline with
+'plan --state', without network access or credentials.`,
+}
+
+// statePullCmd represents the state pull command
+var statePullCmd = &cobra.Command{
+	Use:   "pull",
+	Short: "Save the current custom property values to a snapshot",
+	Long: `State pull fetches every repository of the configuration files and saves
+their custom property values to a JSON snapshot.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		ctx := context.Background()
+		if len(statePullConfigurationFilePaths) == 0 && len(statePullLayers) == 0 {
+			cmd.Println("No configuration files specified. Use --config flag to specify one or more configuration files.")
+			return
+		}
+
+		githubClient, err := newGitHubClient(ctx, cmd)
+		if err != nil {
+			cmd.Printf("Error creating GitHub client: %v\n", err)
+			return
+		}
+		configManager := config.NewConfig(githubClient)
+		configManager.SetProgress(progressPrinter(cmd))
+
+		if err := setVariables(configManager, statePullVariables); err != nil {
+			cmd.Printf("Error: %v\n", err)
+			return
+		}
+
+		// Load all configuration files
+		if err := loadConfigurationFiles(cmd, configManager, statePullConfigurationFilePaths, statePullLayers); err != nil {
+			cmd.Printf("Error loading configuration: %v\n", err)
+			return
+		}
+
+		// Generate repositories
+		if err := configManager.GenerateRepositories(ctx); err != nil {
+			cmd.Printf("Error generating repositories: %v\n", err)
+			return
+		}
+		printWarnings(cmd, configManager)
+
+		snapshot := state.New(configManager, time.Now())
+		if err := writeSnapshot(statePullOutputFilePath, snapshot); err != nil {
+			cmd.Printf("Error writing state snapshot: %v\n", err)
+			return
+		}
+		cmd.Printf("Saved state of %d repositories to %s\n", len(snapshot.Repositories), statePullOutputFilePath)
+	},
+}
+
+// writeSnapshot writes a state snapshot to a file
+func writeSnapshot(path string, snapshot *state.Snapshot) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create %s: %w", path, err)
+	}
+	if err := snapshot.Write(file); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// loadSnapshot reads a state snapshot from a file
+func loadSnapshot(path string) (*state.Snapshot, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open state snapshot %s: %w", path, err)
+	}
+	defer file.Close()
+
+	snapshot, err := state.Load(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load state snapshot from %s: %w", path, err)
+	}
+	return snapshot, nil
+}
+
+func init() {
+	rootCmd.AddCommand(stateCmd)
+	stateCmd.AddCommand(statePullCmd)
+
+	statePullCmd.Flags().StringArrayVar(&statePullConfigurationFilePaths, "config", []string{}, "Configuration file paths (can be specified multiple times)")
+	addLayerFlag(statePullCmd, &statePullLayers)
+	addVariableFlags(statePullCmd, &statePullVariables)
+	statePullCmd.Flags().StringVarP(&statePullOutputFilePath, "output", "o", "state.json", "Path of the snapshot to write")
+}
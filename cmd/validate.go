@@ -0,0 +1,86 @@
+/*
+Copyright © 2025 Hi120ki <12624257+hi120ki@users.noreply.github.com>
+*/
+package cmd
+
+import (
+	"encoding/json"
+	"os"
+
+	"github.com/google/go-github/v74/github"
+	"github.com/hi120ki/gh-custom-property-manager/config"
+	"github.com/spf13/cobra"
+)
+
+var (
+	validateConfigurationFilePaths []string
+	validateLayers                 []string
+	validateVariables              variableFlags
+	validateDefinitionsFilePath    string
+)
+
+// validateCmd represents the validate command
+var validateCmd = &cobra.Command{
+	Use:   "validate",
+	Short: "Validate configuration files without accessing GitHub",
+	Long: `Validate command checks the configuration files offline. It parses each file,
+checks required fields and the 'org/repo' format of repository names, and detects
+repositories configured with conflicting values. No GitHub token is required.
+
+Property values can optionally be checked against a locally cached copy of the
+organization's property definitions, for example:
+  gh api orgs/ORG/properties/schema > definitions.json`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(validateConfigurationFilePaths) == 0 && len(validateLayers) == 0 {
+			cmd.Println("No configuration files specified. Use --config flag to specify one or more configuration files.")
+			os.Exit(1)
+		}
+
+		configManager := config.NewConfig(nil)
+
+		if err := setVariables(configManager, validateVariables); err != nil {
+			cmd.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		// Load all configuration files
+		if err := loadConfigurationFiles(cmd, configManager, validateConfigurationFilePaths, validateLayers); err != nil {
+			cmd.Printf("Error loading configuration: %v\n", err)
+			os.Exit(1)
+		}
+		printWarnings(cmd, configManager)
+
+		// Validate values against the property definitions
+		if validateDefinitionsFilePath != "" {
+			definitionsFile, err := os.Open(validateDefinitionsFilePath)
+			if err != nil {
+				cmd.Printf("Error opening definitions file %s: %v\n", validateDefinitionsFilePath, err)
+				os.Exit(1)
+			}
+			var definitions []*github.CustomProperty
+			err = json.NewDecoder(definitionsFile).Decode(&definitions)
+			definitionsFile.Close()
+			if err != nil {
+				cmd.Printf("Error parsing definitions file %s: %v\n", validateDefinitionsFilePath, err)
+				os.Exit(1)
+			}
+
+			if err := configManager.ValidateDefinitions(definitions); err != nil {
+				cmd.Printf("Error validating values against %s: %v\n", validateDefinitionsFilePath, err)
+				os.Exit(1)
+			}
+		}
+
+		cmd.Println("Configuration is valid.")
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(validateCmd)
+
+	// Add config flag that can be specified multiple times
+	validateCmd.Flags().StringArrayVar(&validateConfigurationFilePaths, "config", []string{}, "Configuration file paths (can be specified multiple times)")
+	addLayerFlag(validateCmd, &validateLayers)
+	addVariableFlags(validateCmd, &validateVariables)
+	validateCmd.Flags().StringVar(&validateDefinitionsFilePath, "definitions", "", "Path to a JSON file with the organization's custom property definitions")
+}
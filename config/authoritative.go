@@ -0,0 +1,140 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/google/go-github/v74/github"
+)
+
+// DefaultMaxRemovals is the default limit of values removed by authoritative
+// configuration files in a single run
+const DefaultMaxRemovals = 10
+
+// SetMaxRemovals sets how many values authoritative configuration files may remove
+// before GenerateDiffs fails. A negative limit disables the check.
+func (c *Config) SetMaxRemovals(maxRemovals int) {
+	c.maxRemovals = maxRemovals
+}
+
+// generateAuthoritativeRepositories lists the repositories of the organizations
+// mentioned by authoritative configuration files, and records those having a value
+// for the property so that GenerateDiffs can remove it. Archived and disabled
+// repositories are left alone. It fails if an organization cannot be listed or holds
+// multi_select values of the property, which could not be pruned.
+func (c *Config) generateAuthoritativeRepositories(ctx context.Context) error {
+	for _, configFile := range c.configurationFiles {
+		if !configFile.Authoritative {
+			continue
+		}
+		for _, value := range configFile.Values {
+			for _, repositoryConfig := range value.Repositories {
+				organizations, err := c.entryOrganizations(ctx, repositoryConfig.entry())
+				if err != nil {
+					return err
+				}
+				for _, organization := range organizations {
+					c.addAuthoritativeOrganization(configFile, organization)
+				}
+			}
+		}
+	}
+
+	for propertyName, organizations := range c.authoritativeOrganizations {
+		logins := make([]string, 0, len(organizations))
+		for _, login := range organizations {
+			logins = append(logins, login)
+		}
+		sort.Strings(logins)
+
+		for i, organization := range logins {
+			// Values of repositories that cannot be listed would silently remain
+			repositories, err := c.organizationRepositories(ctx, organization, i+1, len(logins))
+			if err != nil {
+				return fmt.Errorf("failed to list repositories of organization %s for authoritative property '%s': %w", organization, propertyName, err)
+			}
+			for _, repository := range repositories {
+				if repository.GetArchived() || repository.GetDisabled() {
+					continue
+				}
+				value := repository.CustomProperties[propertyName]
+				if isNonEmptyList(value) {
+					return fmt.Errorf("repository %s has a multi_select value for authoritative property '%s'; authoritative files only support single values", fullName(repository), propertyName)
+				}
+				if c.parseCustomPropertyValue(value) != "" {
+					c.recordRepository(fullName(repository), repository)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// entryOrganizations returns the organizations a repository entry refers to
+func (c *Config) entryOrganizations(ctx context.Context, name string) ([]string, error) {
+	if isRepositorySelector(name) {
+		selector, err := parseRepositorySelector(name)
+		if err != nil {
+			return nil, err
+		}
+		return c.selectorOrganizations(ctx, selector)
+	}
+	// Follow renamed and transferred repositories to their current organization
+	if repository := c.lookupRepository(name); repository != nil {
+		return []string{repository.GetOwner().GetLogin()}, nil
+	}
+	organizationName, _, err := splitRepositoryName(name)
+	if err != nil {
+		return nil, err
+	}
+	return []string{organizationName}, nil
+}
+
+func (c *Config) addAuthoritativeOrganization(configFile *ConfigFile, organization string) {
+	if c.authoritativeOrganizations == nil {
+		c.authoritativeOrganizations = make(map[string]map[string]string)
+	}
+	organizations, exists := c.authoritativeOrganizations[configFile.PropertyName]
+	if !exists {
+		organizations = make(map[string]string)
+		c.authoritativeOrganizations[configFile.PropertyName] = organizations
+	}
+	organizations[strings.ToLower(organization)] = organization
+}
+
+// addRemovals adds the removal of values of authoritative properties that are not
+// configured for the repository to its desired values
+func (c *Config) addRemovals(repository *github.Repository, properties map[string]*desiredValue) map[string]*desiredValue {
+	if repository.GetArchived() || repository.GetDisabled() {
+		return properties
+	}
+	organization := strings.ToLower(repository.GetOwner().GetLogin())
+	for propertyName, organizations := range c.authoritativeOrganizations {
+		if _, affected := organizations[organization]; !affected {
+			continue
+		}
+		if _, configured := properties[propertyName]; configured {
+			continue
+		}
+		if properties == nil {
+			properties = make(map[string]*desiredValue)
+		}
+		properties[propertyName] = &desiredValue{
+			source:  c.authoritativeSource(propertyName),
+			removal: true,
+		}
+	}
+	return properties
+}
+
+// authoritativeSource returns the authoritative configuration file of a property
+func (c *Config) authoritativeSource(propertyName string) string {
+	for _, configFile := range c.configurationFiles {
+		if configFile.Authoritative && configFile.PropertyName == propertyName {
+			return configFile.Source
+		}
+	}
+	return ""
+}
@@ -0,0 +1,131 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/google/go-github/v74/github"
+)
+
+func TestGenerateDiffsAuthoritative(t *testing.T) {
+	mockClient := NewMockGitHubClient()
+	mockClient.AddRepository("org1", "repo1", map[string]interface{}{"team": "frontend"})
+	mockClient.AddRepository("org1", "repo2", map[string]interface{}{"team": "legacy"})
+	mockClient.AddRepository("org1", "repo3", map[string]interface{}{"other": "value"})
+	mockClient.AddRepository("org1", "archived", map[string]interface{}{"team": "legacy"})
+	mockClient.repositories["org1/archived"].Archived = github.Ptr(true)
+	mockClient.AddRepository("org2", "repo1", map[string]interface{}{"team": "legacy"})
+
+	newConfig := func(maxRemovals int) *Config {
+		config := NewConfig(mockClient)
+		config.SetMaxRemovals(maxRemovals)
+		if err := config.AddConfigFile(&ConfigFile{
+			PropertyName:  "team",
+			Authoritative: true,
+			Source:        "team.yaml",
+			Values:        []ValueConfig{{Value: "backend", Repositories: []RepositoryConfig{{Name: "org1/repo1"}}}},
+		}); err != nil {
+			t.Fatalf("AddConfigFile failed: %v", err)
+		}
+		if err := config.GenerateRepositories(context.Background()); err != nil {
+			t.Fatalf("GenerateRepositories failed: %v", err)
+		}
+		return config
+	}
+
+	diffs, err := newConfig(DefaultMaxRemovals).GenerateDiffs(context.Background())
+	if err != nil {
+		t.Fatalf("GenerateDiffs failed: %v", err)
+	}
+	var changes []string
+	for _, diff := range diffs {
+		changes = append(changes, fmt.Sprintf("%s/%s %s: %q -> %q (%s)", diff.Organization, diff.Repository, diff.PropertyName, diff.OldValue, diff.NewValue, diff.Source))
+	}
+	// Repositories of other organizations and archived repositories are left alone
+	expected := []string{
+		`org1/repo1 team: "frontend" -> "backend" (team.yaml)`,
+		`org1/repo2 team: "legacy" -> "" (team.yaml)`,
+	}
+	if strings.Join(changes, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("unexpected diffs:\n%s", strings.Join(changes, "\n"))
+	}
+
+	if _, err := newConfig(0).GenerateDiffs(context.Background()); err == nil || !strings.Contains(err.Error(), "1 values of authoritative properties would be removed") {
+		t.Errorf("expected the removal limit to be exceeded, got %v", err)
+	}
+	if _, err := newConfig(-1).GenerateDiffs(context.Background()); err != nil {
+		t.Errorf("expected no limit, got %v", err)
+	}
+}
+
+func TestGenerateRepositoriesAuthoritativeErrors(t *testing.T) {
+	tests := []struct {
+		name          string
+		setup         func(mockClient *MockGitHubClient)
+		errorContains string
+	}{
+		{
+			name: "organization cannot be listed",
+			setup: func(mockClient *MockGitHubClient) {
+				mockClient.listErrors = map[string]error{"org1": fmt.Errorf("forbidden")}
+			},
+			errorContains: "failed to list repositories of organization org1 for authoritative property 'team': forbidden",
+		},
+		{
+			name: "multi_select value",
+			setup: func(mockClient *MockGitHubClient) {
+				mockClient.AddRepository("org1", "repo2", map[string]interface{}{"team": []any{"backend", "frontend"}})
+			},
+			errorContains: "repository org1/repo2 has a multi_select value for authoritative property 'team'",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockClient := NewMockGitHubClient()
+			mockClient.AddRepository("org1", "repo1", nil)
+			tt.setup(mockClient)
+			config := NewConfig(mockClient)
+			if err := config.AddConfigFile(&ConfigFile{
+				PropertyName:  "team",
+				Authoritative: true,
+				Values:        []ValueConfig{{Value: "backend", Repositories: []RepositoryConfig{{Name: "org1/repo1"}}}},
+			}); err != nil {
+				t.Fatalf("AddConfigFile failed: %v", err)
+			}
+
+			err := config.GenerateRepositories(context.Background())
+			if err == nil || !strings.Contains(err.Error(), tt.errorContains) {
+				t.Errorf("expected an error containing %q, got %v", tt.errorContains, err)
+			}
+		})
+	}
+}
+
+func TestGenerateDiffsAuthoritativeSelector(t *testing.T) {
+	mockClient := NewMockGitHubClient()
+	mockClient.AddRepository("org1", "repo1", map[string]interface{}{"team": "frontend"})
+	mockClient.AddRepository("org2", "repo1", map[string]interface{}{"team": "legacy"})
+
+	config := NewConfig(mockClient)
+	if err := config.AddConfigFile(&ConfigFile{
+		PropertyName:  "team",
+		Authoritative: true,
+		Values:        []ValueConfig{{Value: "frontend", Repositories: []RepositoryConfig{{Name: "org1/*"}}}},
+	}); err != nil {
+		t.Fatalf("AddConfigFile failed: %v", err)
+	}
+	ctx := context.Background()
+	if err := config.GenerateRepositories(ctx); err != nil {
+		t.Fatalf("GenerateRepositories failed: %v", err)
+	}
+	diffs, err := config.GenerateDiffs(ctx)
+	if err != nil {
+		t.Fatalf("GenerateDiffs failed: %v", err)
+	}
+	if len(diffs) != 0 {
+		t.Errorf("expected no diffs, got %d", len(diffs))
+	}
+}
@@ -2,78 +2,239 @@ package config
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
+	"slices"
 	"sort"
 	"strings"
+	"text/template"
 
 	"github.com/goccy/go-yaml"
+	"github.com/goccy/go-yaml/ast"
+	"github.com/goccy/go-yaml/parser"
 	"github.com/google/go-github/v74/github"
 )
 
 // GitHubClient defines the interface for GitHub operations
 type GitHubClient interface {
 	GetRepository(ctx context.Context, org, repo string) *github.Repository
+	GetRepositoryByID(ctx context.Context, org string, id int64) *github.Repository
 	UpdateCustomProperties(ctx context.Context, org, repo string, properties map[string]string) error
+	ListOrganizations(ctx context.Context) ([]string, error)
+	ListEnterpriseOrganizations(ctx context.Context, enterprise string) ([]string, error)
+	ListRepositories(ctx context.Context, org string) ([]*github.Repository, error)
+	ListTeamRepositories(ctx context.Context, org, team string) ([]*github.Repository, error)
 }
 
 type Config struct {
 	githubClient       GitHubClient
 	repositories       []*github.Repository
 	configurationFiles []*ConfigFile
+	repositoryNames    map[string]string
+	repositoryIndex    map[string]*github.Repository
+	renames            []*RepositoryRename
+	repositoryPolicies RepositoryPolicies
+	warnings           []string
+	maxRemovals        int
+	variables          map[string]string
+
+	// applyHook is called after each change is applied
+	applyHook func(ctx context.Context, propertyDiff *PropertyDiff, err error) error
+
+	// Selector expansion state
+	progress                    func(message string)
+	organizationLists           map[string][]string
+	organizationRepositoryLists map[string][]*github.Repository
+	selectorMatches             map[string][]*github.Repository
+	teamRepositoryLists         map[string][]*github.Repository
+
+	// authoritativeOrganizations are the organizations in which values of each
+	// authoritative property are pruned, keyed by property name and lowercase login
+	authoritativeOrganizations map[string]map[string]string
 }
 
 type ConfigFile struct {
-	PropertyName string `yaml:"property_name"`
-	Values       []struct {
-		Value        string `yaml:"value"`
-		Repositories []struct {
-			Name string `yaml:"name"`
-		} `yaml:"repositories"`
-	} `yaml:"values"`
+	PropertyName string        `yaml:"property_name"`
+	Values       []ValueConfig `yaml:"values"`
+	// Authoritative makes the file the only source of values of the property in the
+	// organizations it mentions: values of other repositories are removed
+	Authoritative bool `yaml:"authoritative,omitempty"`
+	// ValuesFrom reads further values from a CSV file mapping repositories to values
+	ValuesFrom *ValuesFrom `yaml:"values_from,omitempty"`
+	// Priority is the priority of the layer the file belongs to. Values of files with
+	// a higher priority override those of lower ones instead of conflicting.
+	Priority int `yaml:"-"`
+	// Literal disables rendering of templates in values, for configuration files
+	// generated from existing values such as backups
+	Literal bool `yaml:"-"`
+	// Source is the path the configuration file was read from, if known
+	Source string `yaml:"-"`
+}
+
+type ValueConfig struct {
+	// Value is the value to set, or a template rendered per repository if it contains '{{'
+	Value        string             `yaml:"value"`
+	Repositories []RepositoryConfig `yaml:"repositories"`
+	// RepositoriesFrom reads further repositories from an external file
+	RepositoriesFrom *RepositoriesFrom `yaml:"repositories_from,omitempty"`
+	// Line is the line of the value in the source file, if known
+	Line int `yaml:"-"`
+	// Literal disables rendering of the value as a template, e.g. for values read
+	// from CSV files
+	Literal bool `yaml:"-"`
+}
+
+// isTemplateValue reports whether a value of a configuration file is a template
+func (configFile *ConfigFile) isTemplateValue(value ValueConfig) bool {
+	return !configFile.Literal && !value.Literal && isTemplate(value.Value)
+}
+
+type RepositoryConfig struct {
+	Name string `yaml:"name,omitempty"`
+	ID   int64  `yaml:"id,omitempty"`
+	// Team selects the repositories a team has access to, as 'org/team-slug'
+	Team string `yaml:"team,omitempty"`
+	// Permission limits a team selector to repositories the team has at least this
+	// permission on: pull, triage, push, maintain or admin
+	Permission string `yaml:"permission,omitempty"`
+}
+
+// entry returns the name identifying a repository entry: the repository name or
+// selector, or 'team:org/team-slug[:permission]' for a team selector
+func (r RepositoryConfig) entry() string {
+	if r.Team == "" {
+		return r.Name
+	}
+	if r.Permission == "" {
+		return teamSelectorPrefix + r.Team
+	}
+	return teamSelectorPrefix + r.Team + ":" + r.Permission
+}
+
+// RepositoryRename describes a configured repository name that resolves to a
+// different canonical name, because the repository was renamed or transferred
+// or because the configured name uses different casing.
+type RepositoryRename struct {
+	ConfiguredName string
+	CanonicalName  string
+	ID             int64
 }
 
 type PropertyDiff struct {
-	Organization string
-	Repository   string
-	PropertyName string
-	OldValue     string
-	NewValue     string
+	Organization string `json:"organization"`
+	Repository   string `json:"repository"`
+	PropertyName string `json:"property"`
+	OldValue     string `json:"old_value"`
+	NewValue     string `json:"new_value"`
+	// Markers describe the state of the repository, such as "archived"
+	Markers []string `json:"markers,omitempty"`
+	// Skipped is set when a repository policy excludes the change from being applied
+	Skipped bool `json:"skipped,omitempty"`
+	// Source is the configuration file setting the new value, if known
+	Source string `json:"config_file,omitempty"`
+	// Explanation describes which configured value won and which ones it overrode
+	Explanation *Explanation `json:"explanation,omitempty"`
 }
 
 func NewConfig(githubClient GitHubClient) *Config {
 	return &Config{
-		githubClient: githubClient,
+		githubClient:       githubClient,
+		repositoryPolicies: DefaultRepositoryPolicies(),
+		maxRemovals:        DefaultMaxRemovals,
 	}
 }
 
+// repositoryKey normalizes a repository name for comparison, as GitHub names are case-insensitive
+func repositoryKey(name string) string {
+	return strings.ToLower(name)
+}
+
+// fullName returns the canonical 'org/repo' name of a repository as returned by the API
+func fullName(repository *github.Repository) string {
+	return fmt.Sprintf("%s/%s", repository.GetOwner().GetLogin(), repository.GetName())
+}
+
+func (c *Config) addWarning(format string, args ...any) {
+	warning := fmt.Sprintf(format, args...)
+	if slices.Contains(c.warnings, warning) {
+		return
+	}
+	c.warnings = append(c.warnings, warning)
+}
+
+// Warnings returns non-fatal problems found while loading configuration files and
+// generating repositories, such as repository names with non-canonical casing.
+func (c *Config) Warnings() []string {
+	return c.warnings
+}
+
+// splitRepositoryName splits a repository name in the format 'org/repo'
+func splitRepositoryName(name string) (string, string, error) {
+	repositoryParts := strings.Split(name, "/")
+	if len(repositoryParts) != 2 || repositoryParts[0] == "" || repositoryParts[1] == "" {
+		return "", "", fmt.Errorf("repository name %s is not in the format 'org/repo'", name)
+	}
+	return repositoryParts[0], repositoryParts[1], nil
+}
+
+func (c *Config) validateConfigFile(configFile *ConfigFile) error {
+	var errs []error
+
+	if configFile.PropertyName == "" {
+		errs = append(errs, fmt.Errorf("property_name is required"))
+	}
+	if len(configFile.Values) == 0 {
+		errs = append(errs, fmt.Errorf("property '%s' has no values", configFile.PropertyName))
+	}
+
+	for i, value := range configFile.Values {
+		if len(value.Repositories) == 0 {
+			errs = append(errs, fmt.Errorf("value #%d ('%s') of property '%s' has no repositories", i+1, value.Value, configFile.PropertyName))
+		}
+		for _, repositoryConfig := range value.Repositories {
+			if err := validateRepositoryEntry(repositoryConfig); err != nil {
+				errs = append(errs, err)
+			}
+		}
+		if configFile.isTemplateValue(value) {
+			if _, err := parseValueTemplate(value.Value); err != nil {
+				errs = append(errs, fmt.Errorf("%s is not a valid template: %w", valueLocation(configFile, i), err))
+			}
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
 func (c *Config) validateNoDuplicateRepositoryValues(configFile *ConfigFile) error {
 	// Check if the same repository is configured with different values in the current configFile
 	repositoryValueMap := make(map[string]string)
 
 	for _, value := range configFile.Values {
 		for _, repositoryConfig := range value.Repositories {
-			repositoryName := repositoryConfig.Name
+			repositoryName := repositoryConfig.entry()
 
-			if existingValue, exists := repositoryValueMap[repositoryName]; exists {
+			if existingValue, exists := repositoryValueMap[repositoryKey(repositoryName)]; exists {
 				if existingValue != value.Value {
 					return fmt.Errorf("repository %s is configured with conflicting values: '%s' and '%s' for property '%s'",
 						repositoryName, existingValue, value.Value, configFile.PropertyName)
 				}
 			} else {
-				repositoryValueMap[repositoryName] = value.Value
+				repositoryValueMap[repositoryKey(repositoryName)] = value.Value
 			}
 		}
 	}
 
 	// Check for duplicates between existing configurationFiles and the new configFile
+	// Files of different layers override each other instead
 	for _, existingConfigFile := range c.configurationFiles {
-		if existingConfigFile.PropertyName == configFile.PropertyName {
+		if existingConfigFile.PropertyName == configFile.PropertyName && existingConfigFile.Priority == configFile.Priority {
 			for _, existingValue := range existingConfigFile.Values {
 				for _, existingRepositoryConfig := range existingValue.Repositories {
-					repositoryName := existingRepositoryConfig.Name
+					repositoryName := existingRepositoryConfig.entry()
 
-					if newValue, exists := repositoryValueMap[repositoryName]; exists {
+					if newValue, exists := repositoryValueMap[repositoryKey(repositoryName)]; exists {
 						if existingValue.Value != newValue {
 							return fmt.Errorf("repository %s is already configured with value '%s' but new config tries to set it to '%s' for property '%s'",
 								repositoryName, existingValue.Value, newValue, configFile.PropertyName)
@@ -88,33 +249,215 @@ func (c *Config) validateNoDuplicateRepositoryValues(configFile *ConfigFile) err
 }
 
 func (c *Config) LoadConfig(r io.Reader) error {
+	return c.LoadLayer(r, 0)
+}
+
+// LoadLayer loads a configuration file belonging to a layer with the given priority
+func (c *Config) LoadLayer(r io.Reader, priority int) error {
 	data, err := io.ReadAll(r)
 	if err != nil {
 		return fmt.Errorf("failed to read config data: %w", err)
 	}
 
+	// Syntax errors are reported by decoding
+	if file, err := parser.ParseBytes(data, 0); err == nil {
+		if err := checkSingleDocument(file); err != nil {
+			return fmt.Errorf("invalid config: %w", err)
+		}
+	}
+
 	var configFile ConfigFile
-	if err := yaml.Unmarshal(data, &configFile); err != nil {
+	if err := yaml.UnmarshalWithOptions(data, &configFile, yaml.DisallowUnknownField()); err != nil {
 		return fmt.Errorf("failed to unmarshal config: %w", err)
 	}
+	if file, ok := r.(interface{ Name() string }); ok {
+		configFile.Source = file.Name()
+	}
+	configFile.Priority = priority
+	setValueLines(data, &configFile)
+	if err := c.interpolateConfigFile(&configFile); err != nil {
+		return fmt.Errorf("invalid config: %w", err)
+	}
+	if err := configFile.loadExternalFiles(); err != nil {
+		return fmt.Errorf("invalid config: %w", err)
+	}
+
+	return c.AddConfigFile(&configFile)
+}
+
+// checkSingleDocument rejects YAML streams with several documents, as a configuration
+// file describes a single property
+func checkSingleDocument(file *ast.File) error {
+	documents := 0
+	for _, doc := range file.Docs {
+		if doc.Body != nil {
+			documents++
+		}
+	}
+	if documents > 1 {
+		return fmt.Errorf("found %d YAML documents; put each property in its own file", documents)
+	}
+	return nil
+}
+
+// AddConfigFile adds a configuration file that was not read from YAML, e.g. one
+// generated from a backup. An empty value unsets the property.
+func (c *Config) AddConfigFile(configFile *ConfigFile) error {
+	// Check required fields and repository name format
+	if err := c.validateConfigFile(configFile); err != nil {
+		return fmt.Errorf("invalid config: %w", err)
+	}
 
 	// Check if the same repository is configured with different values
-	if err := c.validateNoDuplicateRepositoryValues(&configFile); err != nil {
+	if err := c.validateNoDuplicateRepositoryValues(configFile); err != nil {
 		return err
 	}
 
-	c.configurationFiles = append(c.configurationFiles, &configFile)
+	c.lintRepositoryNameCasing(configFile)
+
+	c.configurationFiles = append(c.configurationFiles, configFile)
 
 	return nil
 }
 
-func (c *Config) isRepositoryExists(organizationName, repositoryName string) bool {
+// lintRepositoryNameCasing warns when the same repository is written with different casing
+func (c *Config) lintRepositoryNameCasing(configFile *ConfigFile) {
+	if c.repositoryNames == nil {
+		c.repositoryNames = make(map[string]string)
+	}
+
+	for _, value := range configFile.Values {
+		for _, repositoryConfig := range value.Repositories {
+			name := repositoryConfig.entry()
+			key := repositoryKey(name)
+			existingName, exists := c.repositoryNames[key]
+			if !exists {
+				c.repositoryNames[key] = name
+				continue
+			}
+			if existingName != name {
+				c.addWarning("repository %s is also written as %s; use consistent casing", name, existingName)
+			}
+		}
+	}
+}
+
+func (c *Config) findRepository(organizationName, repositoryName string) *github.Repository {
 	for _, repository := range c.repositories {
-		if repository.GetOwner().GetLogin() == organizationName && repository.GetName() == repositoryName {
-			return true
+		if strings.EqualFold(repository.GetOwner().GetLogin(), organizationName) && strings.EqualFold(repository.GetName(), repositoryName) {
+			return repository
 		}
 	}
-	return false
+	return nil
+}
+
+func (c *Config) findRepositoryByID(id int64) *github.Repository {
+	for _, repository := range c.repositories {
+		if repository.GetID() == id {
+			return repository
+		}
+	}
+	return nil
+}
+
+func (c *Config) isRepositoryExists(organizationName, repositoryName string) bool {
+	return c.findRepository(organizationName, repositoryName) != nil
+}
+
+// lookupRepository returns the repository a configured repository name resolved to
+func (c *Config) lookupRepository(name string) *github.Repository {
+	if repository, exists := c.repositoryIndex[repositoryKey(name)]; exists {
+		return repository
+	}
+	organizationName, repositoryName, err := splitRepositoryName(name)
+	if err != nil {
+		return nil
+	}
+	return c.findRepository(organizationName, repositoryName)
+}
+
+func (c *Config) resolveRepository(ctx context.Context, repositoryConfig RepositoryConfig) (*github.Repository, error) {
+	if repositoryConfig.ID != 0 {
+		if repository := c.findRepositoryByID(repositoryConfig.ID); repository != nil {
+			return repository, nil
+		}
+		// The configured organization selects the credentials, even if the repository was transferred
+		organizationName, _, _ := splitRepositoryName(repositoryConfig.Name)
+		repository := c.githubClient.GetRepositoryByID(ctx, organizationName, repositoryConfig.ID)
+		if repository == nil {
+			return nil, fmt.Errorf("repository %s with id %d not found", repositoryConfig.Name, repositoryConfig.ID)
+		}
+		return repository, nil
+	}
+
+	organizationName, repositoryName, err := splitRepositoryName(repositoryConfig.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	if repository := c.findRepository(organizationName, repositoryName); repository != nil {
+		return repository, nil
+	}
+
+	repository := c.githubClient.GetRepository(ctx, organizationName, repositoryName)
+	if repository == nil {
+		return nil, fmt.Errorf("repository %s not found in organization %s", repositoryName, organizationName)
+	}
+	return repository, nil
+}
+
+// recordRepository remembers which repository a configured name resolved to and
+// detects names that no longer match the canonical name of the repository
+func (c *Config) recordRepository(configuredName string, repository *github.Repository) {
+	if c.repositoryIndex == nil {
+		c.repositoryIndex = make(map[string]*github.Repository)
+	}
+
+	if existing := c.findRepositoryByID(repository.GetID()); existing != nil && repository.GetID() != 0 {
+		repository = existing
+	} else if existing := c.findRepository(repository.GetOwner().GetLogin(), repository.GetName()); existing != nil {
+		repository = existing
+	} else {
+		c.repositories = append(c.repositories, repository)
+	}
+	c.repositoryIndex[repositoryKey(configuredName)] = repository
+
+	canonicalName := fullName(repository)
+	if canonicalName == configuredName {
+		return
+	}
+	c.renames = append(c.renames, &RepositoryRename{
+		ConfiguredName: configuredName,
+		CanonicalName:  canonicalName,
+		ID:             repository.GetID(),
+	})
+	if strings.EqualFold(canonicalName, configuredName) {
+		c.addWarning("repository %s should be written as %s", configuredName, canonicalName)
+	} else {
+		c.addWarning("repository %s has been renamed or transferred to %s", configuredName, canonicalName)
+	}
+}
+
+// Renames returns configured repository names that resolve to a different canonical name
+func (c *Config) Renames() []*RepositoryRename {
+	return c.renames
+}
+
+// Repositories returns the repositories resolved by GenerateRepositories
+func (c *Config) Repositories() []*github.Repository {
+	return c.repositories
+}
+
+// EnterpriseOrganizations returns the organizations listed for enterprise selectors,
+// keyed by enterprise slug
+func (c *Config) EnterpriseOrganizations() map[string][]string {
+	enterprises := make(map[string][]string)
+	for selector, organizations := range c.organizationLists {
+		if enterprise, found := strings.CutPrefix(selector, enterpriseSelectorPrefix); found {
+			enterprises[enterprise] = organizations
+		}
+	}
+	return enterprises
 }
 
 func (c *Config) GenerateRepositories(ctx context.Context) error {
@@ -125,27 +468,26 @@ func (c *Config) GenerateRepositories(ctx context.Context) error {
 	for _, configFile := range c.configurationFiles {
 		for _, value := range configFile.Values {
 			for _, repositoryConfig := range value.Repositories {
-				repositoryParts := strings.Split(repositoryConfig.Name, "/")
-				if len(strings.Split(repositoryConfig.Name, "/")) != 2 {
-					return fmt.Errorf("repository name %s is not in the format 'org/repo'", repositoryConfig.Name)
+				if isRepositorySelector(repositoryConfig.entry()) {
+					if err := c.expandSelector(ctx, repositoryConfig.entry()); err != nil {
+						return err
+					}
+					continue
 				}
-				organizationName := repositoryParts[0]
-				repositoryName := repositoryParts[1]
-
-				if c.isRepositoryExists(organizationName, repositoryName) {
+				if _, exists := c.repositoryIndex[repositoryKey(repositoryConfig.Name)]; exists {
 					continue
 				}
 
-				repository := c.githubClient.GetRepository(ctx, organizationName, repositoryName)
-				if repository == nil {
-					return fmt.Errorf("repository %s not found in organization %s", repositoryName, organizationName)
+				repository, err := c.resolveRepository(ctx, repositoryConfig)
+				if err != nil {
+					return err
 				}
-				c.repositories = append(c.repositories, repository)
+				c.recordRepository(repositoryConfig.Name, repository)
 			}
 		}
 	}
 
-	return nil
+	return c.generateAuthoritativeRepositories(ctx)
 }
 
 func (c *Config) parseCustomPropertyValue(value any) string {
@@ -162,34 +504,52 @@ func (c *Config) GenerateDiffs(ctx context.Context) ([]*PropertyDiff, error) {
 		return nil, fmt.Errorf("no repositories found")
 	}
 
+	desiredValues, err := c.desiredValues()
+	if err != nil {
+		return nil, err
+	}
+
 	var propertyDiffs []*PropertyDiff
+	var removals []*PropertyDiff
 
 	for _, repository := range c.repositories {
-		for _, configFile := range c.configurationFiles {
-			for _, value := range configFile.Values {
-				for _, repositoryConfig := range value.Repositories {
-					if repositoryConfig.Name != fmt.Sprintf("%s/%s", repository.GetOwner().GetLogin(), repository.GetName()) {
-						continue
-					}
-
-					propertyName := configFile.PropertyName
-					oldValue := c.parseCustomPropertyValue(repository.CustomProperties[propertyName])
-					newValue := value.Value
-
-					if oldValue != newValue {
-						propertyDiffs = append(propertyDiffs, &PropertyDiff{
-							Organization: repository.GetOwner().GetLogin(),
-							Repository:   repository.GetName(),
-							PropertyName: propertyName,
-							OldValue:     oldValue,
-							NewValue:     newValue,
-						})
-					}
+		properties := c.addRemovals(repository, desiredValues[repository])
+		propertyNames := make([]string, 0, len(properties))
+		for propertyName := range properties {
+			propertyNames = append(propertyNames, propertyName)
+		}
+		sort.Strings(propertyNames)
+
+		for _, propertyName := range propertyNames {
+			oldValue := c.parseCustomPropertyValue(repository.CustomProperties[propertyName])
+			newValue := properties[propertyName].value
+			source := properties[propertyName].source
+
+			if oldValue != newValue {
+				propertyDiff := &PropertyDiff{
+					Organization: repository.GetOwner().GetLogin(),
+					Repository:   repository.GetName(),
+					PropertyName: propertyName,
+					OldValue:     oldValue,
+					NewValue:     newValue,
+					Source:       source,
+					Explanation:  properties[propertyName].explanation(),
+				}
+				if err := c.applyRepositoryPolicies(repository, propertyDiff); err != nil {
+					return nil, err
+				}
+				propertyDiffs = append(propertyDiffs, propertyDiff)
+				if properties[propertyName].removal {
+					removals = append(removals, propertyDiff)
 				}
 			}
 		}
 	}
 
+	if c.maxRemovals >= 0 && len(removals) > c.maxRemovals {
+		return nil, fmt.Errorf("%d values of authoritative properties would be removed, more than the limit of %d; raise the limit if this is intended", len(removals), c.maxRemovals)
+	}
+
 	sort.Slice(propertyDiffs, func(i, j int) bool {
 		if propertyDiffs[i].Organization != propertyDiffs[j].Organization {
 			return propertyDiffs[i].Organization < propertyDiffs[j].Organization
@@ -203,17 +563,164 @@ func (c *Config) GenerateDiffs(ctx context.Context) ([]*PropertyDiff, error) {
 	return propertyDiffs, nil
 }
 
+// desiredValue is the value a repository entry assigns to a property
+type desiredValue struct {
+	value       string
+	entry       string
+	source      string
+	line        int
+	priority    int
+	specificity int
+	// removal is set for values removed by an authoritative configuration file
+	removal bool
+	// overridden are the values of other entries this value won over
+	overridden []*desiredValue
+}
+
+// desiredValues resolves the value of each property for each repository. When a
+// repository is matched by several entries of a property, the entry of the layer
+// with the highest priority wins. Within a layer, the most specific entry wins: a
+// repository name over a team over an organization selector over a global selector.
+func (c *Config) desiredValues() (map[*github.Repository]map[string]*desiredValue, error) {
+	desiredValues := make(map[*github.Repository]map[string]*desiredValue)
+
+	for _, configFile := range c.configurationFiles {
+		for i, value := range configFile.Values {
+			var valueTemplate *template.Template
+			if configFile.isTemplateValue(value) {
+				var err error
+				if valueTemplate, err = parseValueTemplate(value.Value); err != nil {
+					return nil, fmt.Errorf("%s is not a valid template: %w", valueLocation(configFile, i), err)
+				}
+			}
+
+			for _, repositoryConfig := range value.Repositories {
+				for _, repository := range c.matchRepositories(repositoryConfig.entry()) {
+					candidate := &desiredValue{
+						value:       value.Value,
+						entry:       repositoryConfig.entry(),
+						source:      configFile.Source,
+						line:        value.Line,
+						priority:    configFile.Priority,
+						specificity: repositorySpecificity(repositoryConfig.entry()),
+					}
+					if valueTemplate != nil {
+						rendered, err := renderValue(valueTemplate, repository)
+						if err != nil {
+							return nil, fmt.Errorf("failed to render %s for repository %s: %w", valueLocation(configFile, i), fullName(repository), err)
+						}
+						candidate.value = rendered
+					}
+
+					properties, exists := desiredValues[repository]
+					if !exists {
+						properties = make(map[string]*desiredValue)
+						desiredValues[repository] = properties
+					}
+
+					existing := properties[configFile.PropertyName]
+					switch {
+					case existing == nil:
+						properties[configFile.PropertyName] = candidate
+					case candidate.overrides(existing):
+						candidate.overridden = append(existing.overridden, existing)
+						existing.overridden = nil
+						properties[configFile.PropertyName] = candidate
+					case existing.overrides(candidate):
+						existing.overridden = append(existing.overridden, candidate)
+					case candidate.value != existing.value:
+						return nil, fmt.Errorf("repository %s is matched by %s and %s with conflicting values '%s' and '%s' for property '%s'",
+							fullName(repository), existing.entry, candidate.entry, existing.value, candidate.value, configFile.PropertyName)
+					}
+				}
+			}
+		}
+	}
+
+	return desiredValues, nil
+}
+
 func (c *Config) ApplyChange(ctx context.Context, propertyDiff *PropertyDiff) error {
 	if propertyDiff == nil {
 		return fmt.Errorf("property diff is nil")
 	}
+	if propertyDiff.Skipped {
+		return c.notifyApply(ctx, propertyDiff, nil)
+	}
 
 	propertyUpdates := map[string]string{
 		propertyDiff.PropertyName: propertyDiff.NewValue,
 	}
 	if err := c.githubClient.UpdateCustomProperties(ctx, propertyDiff.Organization, propertyDiff.Repository, propertyUpdates); err != nil {
-		return fmt.Errorf("failed to update property %s for repository %s/%s: %w", propertyDiff.PropertyName, propertyDiff.Organization, propertyDiff.Repository, err)
+		err = fmt.Errorf("failed to update property %s for repository %s/%s: %w", propertyDiff.PropertyName, propertyDiff.Organization, propertyDiff.Repository, err)
+		return errors.Join(err, c.notifyApply(ctx, propertyDiff, err))
+	}
+
+	if err := c.notifyApply(ctx, propertyDiff, nil); err != nil {
+		return fmt.Errorf("updated property %s for repository %s/%s but failed to record it: %w", propertyDiff.PropertyName, propertyDiff.Organization, propertyDiff.Repository, err)
+	}
+	return nil
+}
+
+// SetApplyHook sets a function called after each change passed to ApplyChange,
+// with the error if the change failed. Skipped changes are reported too. An error
+// returned by the hook is returned by ApplyChange, so that callers stop applying.
+func (c *Config) SetApplyHook(hook func(ctx context.Context, propertyDiff *PropertyDiff, err error) error) {
+	c.applyHook = hook
+}
+
+func (c *Config) notifyApply(ctx context.Context, propertyDiff *PropertyDiff, err error) error {
+	if c.applyHook != nil {
+		return c.applyHook(ctx, propertyDiff, err)
+	}
+	return nil
+}
+
+// ValidateDefinitions checks the loaded configuration files against custom property
+// definitions, such as a locally cached copy of an organization's property schema.
+func (c *Config) ValidateDefinitions(definitions []*github.CustomProperty) error {
+	definitionMap := make(map[string]*github.CustomProperty, len(definitions))
+	for _, definition := range definitions {
+		definitionMap[definition.GetPropertyName()] = definition
+	}
+
+	var errs []error
+	for _, configFile := range c.configurationFiles {
+		definition, exists := definitionMap[configFile.PropertyName]
+		if !exists {
+			errs = append(errs, fmt.Errorf("property '%s' is not defined", configFile.PropertyName))
+			continue
+		}
+		if configFile.Authoritative && definition.ValueType == "multi_select" {
+			errs = append(errs, fmt.Errorf("property '%s' is a multi_select property, which cannot be authoritative", configFile.PropertyName))
+		}
+
+		for _, value := range configFile.Values {
+			// Templated values are only known once rendered for each repository
+			if configFile.isTemplateValue(value) {
+				continue
+			}
+			if err := validateValue(definition, value.Value); err != nil {
+				errs = append(errs, err)
+			}
+		}
 	}
 
+	return errors.Join(errs...)
+}
+
+func validateValue(definition *github.CustomProperty, value string) error {
+	switch definition.ValueType {
+	case "single_select":
+		if !slices.Contains(definition.AllowedValues, value) {
+			return fmt.Errorf("value '%s' is not allowed for property '%s' (allowed values: %s)",
+				value, definition.GetPropertyName(), strings.Join(definition.AllowedValues, ", "))
+		}
+	case "true_false":
+		if value != "true" && value != "false" {
+			return fmt.Errorf("value '%s' is not allowed for property '%s' (expected 'true' or 'false')",
+				value, definition.GetPropertyName())
+		}
+	}
 	return nil
 }
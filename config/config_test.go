@@ -3,6 +3,8 @@ package config
 import (
 	"context"
 	"fmt"
+	"slices"
+	"sort"
 	"strings"
 	"testing"
 
@@ -11,8 +13,11 @@ import (
 
 // MockGitHubClient is a mock implementation of the GitHubClient interface
 type MockGitHubClient struct {
-	repositories map[string]*github.Repository
-	updateError  error
+	repositories            map[string]*github.Repository
+	updateError             error
+	enterpriseOrganizations map[string][]string
+	listErrors              map[string]error
+	teamRepositories        map[string][]*github.Repository
 }
 
 func NewMockGitHubClient() *MockGitHubClient {
@@ -22,14 +27,79 @@ func NewMockGitHubClient() *MockGitHubClient {
 }
 
 func (m *MockGitHubClient) GetRepository(ctx context.Context, org, repo string) *github.Repository {
-	key := fmt.Sprintf("%s/%s", org, repo)
+	// GitHub resolves repository names case-insensitively
+	key := strings.ToLower(fmt.Sprintf("%s/%s", org, repo))
 	return m.repositories[key]
 }
 
+func (m *MockGitHubClient) GetRepositoryByID(ctx context.Context, org string, id int64) *github.Repository {
+	for _, repository := range m.repositories {
+		if repository.GetID() == id {
+			return repository
+		}
+	}
+	return nil
+}
+
 func (m *MockGitHubClient) UpdateCustomProperties(ctx context.Context, org, repo string, properties map[string]string) error {
 	return m.updateError
 }
 
+func (m *MockGitHubClient) ListOrganizations(ctx context.Context) ([]string, error) {
+	var organizations []string
+	for _, repository := range m.repositories {
+		organization := repository.GetOwner().GetLogin()
+		if !slices.Contains(organizations, organization) {
+			organizations = append(organizations, organization)
+		}
+	}
+	sort.Strings(organizations)
+	return organizations, nil
+}
+
+func (m *MockGitHubClient) ListEnterpriseOrganizations(ctx context.Context, enterprise string) ([]string, error) {
+	organizations, exists := m.enterpriseOrganizations[enterprise]
+	if !exists {
+		return nil, fmt.Errorf("enterprise %s not found", enterprise)
+	}
+	return organizations, nil
+}
+
+func (m *MockGitHubClient) ListRepositories(ctx context.Context, org string) ([]*github.Repository, error) {
+	if err := m.listErrors[org]; err != nil {
+		return nil, err
+	}
+	var repositories []*github.Repository
+	for _, repository := range m.repositories {
+		if strings.EqualFold(repository.GetOwner().GetLogin(), org) {
+			repositories = append(repositories, repository)
+		}
+	}
+	sort.Slice(repositories, func(i, j int) bool {
+		return repositories[i].GetName() < repositories[j].GetName()
+	})
+	return repositories, nil
+}
+
+func (m *MockGitHubClient) ListTeamRepositories(ctx context.Context, org, team string) ([]*github.Repository, error) {
+	repositories, exists := m.teamRepositories[strings.ToLower(org+"/"+team)]
+	if !exists {
+		return nil, fmt.Errorf("team %s/%s not found", org, team)
+	}
+	return repositories, nil
+}
+
+// AddTeamRepository gives a team access to a repository added with AddRepository
+func (m *MockGitHubClient) AddTeamRepository(org, team, repo, permission string) {
+	if m.teamRepositories == nil {
+		m.teamRepositories = make(map[string][]*github.Repository)
+	}
+	repository := *m.repositories[strings.ToLower(org+"/"+repo)]
+	repository.Permissions = map[string]bool{permission: true}
+	key := strings.ToLower(org + "/" + team)
+	m.teamRepositories[key] = append(m.teamRepositories[key], &repository)
+}
+
 func (m *MockGitHubClient) AddRepository(org, repo string, customProperties map[string]interface{}) {
 	owner := &github.User{Login: github.Ptr(org)}
 	repository := &github.Repository{
@@ -37,7 +107,7 @@ func (m *MockGitHubClient) AddRepository(org, repo string, customProperties map[
 		Owner:            owner,
 		CustomProperties: customProperties,
 	}
-	key := fmt.Sprintf("%s/%s", org, repo)
+	key := strings.ToLower(fmt.Sprintf("%s/%s", org, repo))
 	m.repositories[key] = repository
 }
 
@@ -907,3 +977,300 @@ values:
 		})
 	}
 }
+
+func TestLoadConfigSchemaValidation(t *testing.T) {
+	tests := []struct {
+		name          string
+		yamlContent   string
+		errorContains string
+	}{
+		{
+			name: "missing property name",
+			yamlContent: `values:
+  - value: "backend"
+    repositories:
+      - name: "org1/repo1"`,
+			errorContains: "property_name is required",
+		},
+		{
+			name:          "no values",
+			yamlContent:   `property_name: "team"`,
+			errorContains: "property 'team' has no values",
+		},
+		{
+			name: "value without repositories",
+			yamlContent: `property_name: "team"
+values:
+  - value: "backend"`,
+			errorContains: "value #1 ('backend') of property 'team' has no repositories",
+		},
+		{
+			name: "invalid repository format",
+			yamlContent: `property_name: "team"
+values:
+  - value: "backend"
+    repositories:
+      - name: "org1/"`,
+			errorContains: "is not in the format 'org/repo'",
+		},
+		{
+			name: "unknown field",
+			yamlContent: `property_name: "team"
+values:
+  - value: "backend"
+    repository:
+      - name: "org1/repo1"`,
+			errorContains: "failed to unmarshal config",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := NewConfig(nil)
+
+			err := config.LoadConfig(strings.NewReader(tt.yamlContent))
+			if err == nil {
+				t.Fatal("expected error but got none")
+			}
+			if !strings.Contains(err.Error(), tt.errorContains) {
+				t.Errorf("expected error to contain %q, got %q", tt.errorContains, err.Error())
+			}
+		})
+	}
+}
+
+func TestValidateDefinitions(t *testing.T) {
+	definitions := []*github.CustomProperty{
+		{
+			PropertyName:  github.Ptr("team"),
+			ValueType:     "single_select",
+			AllowedValues: []string{"backend", "frontend"},
+		},
+		{
+			PropertyName: github.Ptr("public"),
+			ValueType:    "true_false",
+		},
+		{
+			PropertyName: github.Ptr("owner"),
+			ValueType:    "string",
+		},
+		{
+			PropertyName: github.Ptr("languages"),
+			ValueType:    "multi_select",
+		},
+	}
+
+	tests := []struct {
+		name          string
+		yamlContent   string
+		errorContains string
+	}{
+		{
+			name: "allowed single select value",
+			yamlContent: `property_name: "team"
+values:
+  - value: "backend"
+    repositories:
+      - name: "org1/repo1"`,
+		},
+		{
+			name: "disallowed single select value",
+			yamlContent: `property_name: "team"
+values:
+  - value: "infra"
+    repositories:
+      - name: "org1/repo1"`,
+			errorContains: "value 'infra' is not allowed for property 'team'",
+		},
+		{
+			name: "invalid true false value",
+			yamlContent: `property_name: "public"
+values:
+  - value: "yes"
+    repositories:
+      - name: "org1/repo1"`,
+			errorContains: "expected 'true' or 'false'",
+		},
+		{
+			name: "free form string value",
+			yamlContent: `property_name: "owner"
+values:
+  - value: "anything goes"
+    repositories:
+      - name: "org1/repo1"`,
+		},
+		{
+			name: "templated single select value",
+			yamlContent: `property_name: "team"
+values:
+  - value: "{{ .Language | lower }}"
+    repositories:
+      - name: "org1/repo1"`,
+		},
+		{
+			name: "templated and disallowed single select values",
+			yamlContent: `property_name: "team"
+values:
+  - value: "{{ .Language | lower }}"
+    repositories:
+      - name: "org1/repo1"
+  - value: "infra"
+    repositories:
+      - name: "org1/repo2"`,
+			errorContains: "value 'infra' is not allowed for property 'team'",
+		},
+		{
+			name: "undefined property",
+			yamlContent: `property_name: "environment"
+values:
+  - value: "production"
+    repositories:
+      - name: "org1/repo1"`,
+			errorContains: "property 'environment' is not defined",
+		},
+		{
+			name: "authoritative multi select property",
+			yamlContent: `property_name: "languages"
+authoritative: true
+values:
+  - value: "go"
+    repositories:
+      - name: "org1/repo1"`,
+			errorContains: "property 'languages' is a multi_select property, which cannot be authoritative",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := NewConfig(nil)
+			if err := config.LoadConfig(strings.NewReader(tt.yamlContent)); err != nil {
+				t.Fatalf("LoadConfig failed: %v", err)
+			}
+
+			err := config.ValidateDefinitions(definitions)
+
+			if tt.errorContains == "" {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+			} else if err == nil {
+				t.Errorf("expected error but got none")
+			} else if !strings.Contains(err.Error(), tt.errorContains) {
+				t.Errorf("expected error to contain %q, got %q", tt.errorContains, err.Error())
+			}
+		})
+	}
+}
+
+func TestCaseInsensitiveRepositoryMatching(t *testing.T) {
+	mockClient := NewMockGitHubClient()
+	mockClient.AddRepository("myorg", "service", map[string]interface{}{"team": "old"})
+
+	config := NewConfig(mockClient)
+	configContent := `property_name: "team"
+values:
+  - value: "backend"
+    repositories:
+      - name: "MyOrg/Service"
+      - name: "myorg/service"`
+	if err := config.LoadConfig(strings.NewReader(configContent)); err != nil {
+		t.Fatalf("LoadConfig failed: %v", err)
+	}
+
+	if err := config.GenerateRepositories(context.Background()); err != nil {
+		t.Fatalf("GenerateRepositories failed: %v", err)
+	}
+	if len(config.repositories) != 1 {
+		t.Fatalf("expected 1 repository, got %d", len(config.repositories))
+	}
+
+	diffs, err := config.GenerateDiffs(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(diffs) != 1 {
+		t.Fatalf("expected 1 diff, got %d", len(diffs))
+	}
+	if diffs[0].Organization != "myorg" || diffs[0].Repository != "service" {
+		t.Errorf("expected diff for canonical name myorg/service, got %s/%s", diffs[0].Organization, diffs[0].Repository)
+	}
+
+	warnings := strings.Join(config.Warnings(), "\n")
+	if !strings.Contains(warnings, "repository MyOrg/Service should be written as myorg/service") {
+		t.Errorf("expected canonical casing warning, got %q", warnings)
+	}
+	if !strings.Contains(warnings, "repository myorg/service is also written as MyOrg/Service") {
+		t.Errorf("expected inconsistent casing warning, got %q", warnings)
+	}
+}
+
+func TestLoadConfigCaseInsensitiveConflict(t *testing.T) {
+	config := NewConfig(NewMockGitHubClient())
+
+	if err := config.LoadConfig(strings.NewReader(`property_name: "team"
+values:
+  - value: "backend"
+    repositories:
+      - name: "MyOrg/Service"`)); err != nil {
+		t.Fatalf("LoadConfig failed: %v", err)
+	}
+
+	err := config.LoadConfig(strings.NewReader(`property_name: "team"
+values:
+  - value: "frontend"
+    repositories:
+      - name: "myorg/service"`))
+	if err == nil {
+		t.Fatal("expected error but got none")
+	}
+	if !strings.Contains(err.Error(), "is already configured with value") {
+		t.Errorf("expected error to contain 'is already configured with value', got %q", err.Error())
+	}
+}
+
+func TestApplyHook(t *testing.T) {
+	mockClient := NewMockGitHubClient()
+	mockClient.AddRepository("org1", "repo1", nil)
+	config := NewConfig(mockClient)
+
+	var applied []string
+	config.SetApplyHook(func(ctx context.Context, diff *PropertyDiff, err error) error {
+		applied = append(applied, fmt.Sprintf("%s/%s skipped=%t err=%v", diff.Organization, diff.Repository, diff.Skipped, err))
+		return nil
+	})
+
+	ctx := context.Background()
+	if err := config.ApplyChange(ctx, &PropertyDiff{Organization: "org1", Repository: "repo1", PropertyName: "team", NewValue: "backend"}); err != nil {
+		t.Fatalf("ApplyChange failed: %v", err)
+	}
+	if err := config.ApplyChange(ctx, &PropertyDiff{Organization: "org1", Repository: "repo2", PropertyName: "team", Skipped: true}); err != nil {
+		t.Fatalf("ApplyChange failed: %v", err)
+	}
+	mockClient.SetUpdateError(fmt.Errorf("API error"))
+	if err := config.ApplyChange(ctx, &PropertyDiff{Organization: "org1", Repository: "repo1", PropertyName: "team", NewValue: "frontend"}); err == nil {
+		t.Fatal("expected ApplyChange to fail")
+	}
+
+	expected := []string{
+		"org1/repo1 skipped=false err=<nil>",
+		"org1/repo2 skipped=true err=<nil>",
+		"org1/repo1 skipped=false err=failed to update property team for repository org1/repo1: API error",
+	}
+	if strings.Join(applied, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("unexpected hook calls:\n%s", strings.Join(applied, "\n"))
+	}
+}
+
+func TestApplyHookError(t *testing.T) {
+	mockClient := NewMockGitHubClient()
+	mockClient.AddRepository("org1", "repo1", nil)
+	config := NewConfig(mockClient)
+	config.SetApplyHook(func(ctx context.Context, diff *PropertyDiff, err error) error {
+		return fmt.Errorf("disk full")
+	})
+
+	err := config.ApplyChange(context.Background(), &PropertyDiff{Organization: "org1", Repository: "repo1", PropertyName: "team", NewValue: "backend"})
+	if err == nil || !strings.Contains(err.Error(), "updated property team for repository org1/repo1 but failed to record it: disk full") {
+		t.Errorf("expected the hook error, got %v", err)
+	}
+}
@@ -0,0 +1,128 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/google/go-github/v74/github"
+)
+
+// Coverage reports how completely the configured properties cover the repositories
+// of organizations
+type Coverage struct {
+	Organizations []string            `json:"organizations"`
+	Repositories  int                 `json:"repositories"`
+	Properties    []*PropertyCoverage `json:"properties"`
+	// Unmanaged are repositories not configured for any property
+	Unmanaged []string `json:"unmanaged"`
+}
+
+// PropertyCoverage reports the coverage of a single property
+type PropertyCoverage struct {
+	PropertyName string `json:"property"`
+	// Configured is the number of repositories a configuration file assigns a value to
+	Configured int `json:"configured"`
+	// WithValue is the number of repositories currently having a value
+	WithValue  int     `json:"with_value"`
+	Percentage float64 `json:"percentage"`
+	// Missing are repositories currently without a value
+	Missing []string `json:"missing"`
+	// Unconfigured are repositories no configuration file assigns a value to
+	Unconfigured []string `json:"unconfigured"`
+}
+
+// Coverage compares all repositories of the organizations with the loaded
+// configuration files and their current values. It must be called after
+// GenerateRepositories. Archived and disabled repositories are not counted, as
+// their properties cannot be changed.
+func (c *Config) Coverage(ctx context.Context, organizations []string) (*Coverage, error) {
+	desiredValues, err := c.desiredValues()
+	if err != nil {
+		return nil, err
+	}
+	configured := make(map[string]map[string]bool)
+	for repository, properties := range desiredValues {
+		propertyNames := make(map[string]bool, len(properties))
+		for propertyName := range properties {
+			propertyNames[propertyName] = true
+		}
+		configured[repositoryKey(fullName(repository))] = propertyNames
+	}
+
+	var propertyNames []string
+	for _, configFile := range c.configurationFiles {
+		propertyNames = append(propertyNames, configFile.PropertyName)
+	}
+	// A property may be configured in several files or layers
+	sort.Strings(propertyNames)
+	propertyNames = slices.Compact(propertyNames)
+
+	var repositories []*github.Repository
+	for i, organization := range organizations {
+		c.reportProgress("Listing repositories of organization %s (%d/%d)", organization, i+1, len(organizations))
+		organizationRepositories, err := c.githubClient.ListRepositories(ctx, organization)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list repositories of organization %s: %w", organization, err)
+		}
+		for _, repository := range organizationRepositories {
+			if !repository.GetArchived() && !repository.GetDisabled() {
+				repositories = append(repositories, repository)
+			}
+		}
+	}
+	sort.Slice(repositories, func(i, j int) bool {
+		return strings.ToLower(fullName(repositories[i])) < strings.ToLower(fullName(repositories[j]))
+	})
+
+	coverage := &Coverage{
+		Organizations: organizations,
+		Repositories:  len(repositories),
+		Unmanaged:     []string{},
+	}
+	for _, propertyName := range propertyNames {
+		propertyCoverage := &PropertyCoverage{
+			PropertyName: propertyName,
+			Missing:      []string{},
+			Unconfigured: []string{},
+		}
+		for _, repository := range repositories {
+			name := fullName(repository)
+			if configured[repositoryKey(name)][propertyName] {
+				propertyCoverage.Configured++
+			} else {
+				propertyCoverage.Unconfigured = append(propertyCoverage.Unconfigured, name)
+			}
+			if c.parseCustomPropertyValue(repository.CustomProperties[propertyName]) != "" || isNonEmptyList(repository.CustomProperties[propertyName]) {
+				propertyCoverage.WithValue++
+			} else {
+				propertyCoverage.Missing = append(propertyCoverage.Missing, name)
+			}
+		}
+		if len(repositories) > 0 {
+			propertyCoverage.Percentage = float64(propertyCoverage.WithValue) * 100 / float64(len(repositories))
+		}
+		coverage.Properties = append(coverage.Properties, propertyCoverage)
+	}
+
+	for _, repository := range repositories {
+		if len(configured[repositoryKey(fullName(repository))]) == 0 {
+			coverage.Unmanaged = append(coverage.Unmanaged, fullName(repository))
+		}
+	}
+	return coverage, nil
+}
+
+// isNonEmptyList reports whether a property value is a non-empty multi_select value
+func isNonEmptyList(value any) bool {
+	switch v := value.(type) {
+	case []string:
+		return len(v) > 0
+	case []any:
+		return len(v) > 0
+	default:
+		return false
+	}
+}
@@ -0,0 +1,96 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/google/go-github/v74/github"
+)
+
+func TestCoverage(t *testing.T) {
+	mockClient := NewMockGitHubClient()
+	mockClient.AddRepository("org1", "repo1", map[string]interface{}{"team": "backend", "tier": "1"})
+	mockClient.AddRepository("org1", "repo2", map[string]interface{}{"team": "frontend"})
+	mockClient.AddRepository("org1", "repo3", nil)
+	mockClient.AddRepository("org1", "archived", nil)
+	mockClient.repositories["org1/archived"].Archived = github.Ptr(true)
+	mockClient.AddRepository("org2", "other", nil)
+
+	config := NewConfig(mockClient)
+	for _, configFile := range []*ConfigFile{
+		{PropertyName: "team", Values: []ValueConfig{{Value: "backend", Repositories: []RepositoryConfig{{Name: "org1/repo1"}, {Name: "org1/repo3"}}}}},
+		{PropertyName: "tier", Values: []ValueConfig{{Value: "1", Repositories: []RepositoryConfig{{Name: "org1/repo1"}, {Name: "org2/other"}}}}},
+	} {
+		if err := config.AddConfigFile(configFile); err != nil {
+			t.Fatalf("AddConfigFile failed: %v", err)
+		}
+	}
+	ctx := context.Background()
+	if err := config.GenerateRepositories(ctx); err != nil {
+		t.Fatalf("GenerateRepositories failed: %v", err)
+	}
+
+	coverage, err := config.Coverage(ctx, []string{"org1"})
+	if err != nil {
+		t.Fatalf("Coverage failed: %v", err)
+	}
+	if coverage.Repositories != 3 {
+		t.Errorf("expected 3 repositories, got %d", coverage.Repositories)
+	}
+	if len(coverage.Properties) != 2 {
+		t.Fatalf("expected 2 properties, got %d", len(coverage.Properties))
+	}
+
+	team := coverage.Properties[0]
+	if team.PropertyName != "team" || team.WithValue != 2 || team.Configured != 2 || fmt.Sprintf("%.1f", team.Percentage) != "66.7" {
+		t.Errorf("unexpected coverage of team: %+v", team)
+	}
+	if !reflect.DeepEqual(team.Missing, []string{"org1/repo3"}) || !reflect.DeepEqual(team.Unconfigured, []string{"org1/repo2"}) {
+		t.Errorf("unexpected uncovered repositories of team: %+v", team)
+	}
+	tier := coverage.Properties[1]
+	if tier.WithValue != 1 || tier.Configured != 1 || !reflect.DeepEqual(tier.Missing, []string{"org1/repo2", "org1/repo3"}) {
+		t.Errorf("unexpected coverage of tier: %+v", tier)
+	}
+	if !reflect.DeepEqual(coverage.Unmanaged, []string{"org1/repo2"}) {
+		t.Errorf("expected org1/repo2 to be unmanaged, got %v", coverage.Unmanaged)
+	}
+
+	mockClient.listErrors = map[string]error{"org1": fmt.Errorf("forbidden")}
+	if _, err := config.Coverage(ctx, []string{"org1"}); err == nil {
+		t.Error("expected an error when an organization cannot be listed")
+	}
+}
+
+func TestCoveragePropertyInSeveralFiles(t *testing.T) {
+	mockClient := NewMockGitHubClient()
+	mockClient.AddRepository("org1", "repo1", map[string]interface{}{"team": "backend"})
+	mockClient.AddRepository("org1", "repo2", nil)
+
+	config := NewConfig(mockClient)
+	for _, configFile := range []*ConfigFile{
+		{PropertyName: "team", Values: []ValueConfig{{Value: "backend", Repositories: []RepositoryConfig{{Name: "org1/repo1"}}}}},
+		{PropertyName: "team", Values: []ValueConfig{{Value: "frontend", Repositories: []RepositoryConfig{{Name: "org1/repo2"}}}}},
+	} {
+		if err := config.AddConfigFile(configFile); err != nil {
+			t.Fatalf("AddConfigFile failed: %v", err)
+		}
+	}
+	ctx := context.Background()
+	if err := config.GenerateRepositories(ctx); err != nil {
+		t.Fatalf("GenerateRepositories failed: %v", err)
+	}
+
+	coverage, err := config.Coverage(ctx, []string{"org1"})
+	if err != nil {
+		t.Fatalf("Coverage failed: %v", err)
+	}
+	if len(coverage.Properties) != 1 {
+		t.Fatalf("expected the property to be reported once, got %d", len(coverage.Properties))
+	}
+	if team := coverage.Properties[0]; team.Configured != 2 || team.WithValue != 1 || !reflect.DeepEqual(team.Missing, []string{"org1/repo2"}) {
+		t.Errorf("unexpected coverage of team: %+v", team)
+	}
+}
@@ -0,0 +1,210 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/google/go-github/v74/github"
+)
+
+// RepositoryExplanation describes where the desired values of the properties of a
+// repository come from
+type RepositoryExplanation struct {
+	Repository string                 `json:"repository"`
+	Properties []*PropertyExplanation `json:"properties"`
+}
+
+// PropertyExplanation describes the desired and current value of a property
+type PropertyExplanation struct {
+	PropertyName string `json:"property"`
+	CurrentValue string `json:"current_value"`
+	DesiredValue string `json:"desired_value"`
+	// Configured is set when a configuration file sets or removes the value
+	Configured bool `json:"configured"`
+	// Removal is set when an authoritative configuration file removes the value
+	Removal bool   `json:"removal,omitempty"`
+	Source  string `json:"config_file,omitempty"`
+	// Winner is the configured value that was chosen, and Overridden the ones it won over
+	Winner     *Candidate   `json:"winner,omitempty"`
+	Overridden []*Candidate `json:"overridden,omitempty"`
+	// Excluded are configured values whose entries would match the repository but
+	// do not apply to it
+	Excluded []*ExcludedCandidate `json:"excluded,omitempty"`
+	// Markers and Skipped are set as in a planned change by the repository policies
+	Markers []string `json:"markers,omitempty"`
+	Skipped bool     `json:"skipped,omitempty"`
+	// Blocked is the error a repository policy raises for a change
+	Blocked string `json:"blocked,omitempty"`
+}
+
+// ExcludedCandidate is a configured value that does not apply to a repository
+type ExcludedCandidate struct {
+	Candidate
+	Reason string `json:"reason"`
+}
+
+// Explain describes the desired values of the properties of a repository, or of a
+// single property if propertyName is not empty. GenerateRepositories must be called
+// first; repositories no configuration file matches are looked up.
+func (c *Config) Explain(ctx context.Context, name, propertyName string) (*RepositoryExplanation, error) {
+	repository := c.lookupRepository(name)
+	if repository == nil {
+		organizationName, repositoryName, err := splitRepositoryName(name)
+		if err != nil {
+			return nil, err
+		}
+		if repository = c.githubClient.GetRepository(ctx, organizationName, repositoryName); repository == nil {
+			return nil, fmt.Errorf("repository %s not found in organization %s", repositoryName, organizationName)
+		}
+	}
+
+	desiredValues, err := c.desiredValues()
+	if err != nil {
+		return nil, err
+	}
+	properties := c.addRemovals(repository, desiredValues[repository])
+	excluded := c.excludedCandidates(repository)
+
+	propertyNames := make(map[string]bool)
+	for property := range properties {
+		propertyNames[property] = true
+	}
+	for property := range repository.CustomProperties {
+		propertyNames[property] = true
+	}
+	for property := range excluded {
+		propertyNames[property] = true
+	}
+	if propertyName != "" {
+		propertyNames = map[string]bool{propertyName: true}
+	}
+
+	explanation := &RepositoryExplanation{Repository: fullName(repository)}
+	for property := range propertyNames {
+		propertyExplanation := &PropertyExplanation{
+			PropertyName: property,
+			CurrentValue: c.parseCustomPropertyValue(repository.CustomProperties[property]),
+			Excluded:     excluded[property],
+		}
+		if desired := properties[property]; desired != nil {
+			propertyExplanation.DesiredValue = desired.value
+			propertyExplanation.Configured = true
+			propertyExplanation.Removal = desired.removal
+			propertyExplanation.Source = desired.source
+			if desiredExplanation := desired.explanation(); desiredExplanation != nil {
+				propertyExplanation.Winner = desiredExplanation.Winner
+				propertyExplanation.Overridden = desiredExplanation.Overridden
+			}
+			if err := c.explainRepositoryPolicies(repository, propertyExplanation); err != nil {
+				propertyExplanation.Blocked = err.Error()
+			}
+		}
+		explanation.Properties = append(explanation.Properties, propertyExplanation)
+	}
+	sort.Slice(explanation.Properties, func(i, j int) bool {
+		return explanation.Properties[i].PropertyName < explanation.Properties[j].PropertyName
+	})
+
+	return explanation, nil
+}
+
+// explainRepositoryPolicies applies the repository policies to the change a
+// property explanation describes, if any
+func (c *Config) explainRepositoryPolicies(repository *github.Repository, propertyExplanation *PropertyExplanation) error {
+	if propertyExplanation.CurrentValue == propertyExplanation.DesiredValue {
+		return nil
+	}
+	propertyDiff := &PropertyDiff{PropertyName: propertyExplanation.PropertyName}
+	err := c.applyRepositoryPolicies(repository, propertyDiff)
+	propertyExplanation.Markers = propertyDiff.Markers
+	propertyExplanation.Skipped = propertyDiff.Skipped
+	return err
+}
+
+// excludedCandidates returns, by property name, the values of selectors that would
+// match the repository but skip it: archived and disabled repositories are never
+// matched, and team selectors require a permission
+func (c *Config) excludedCandidates(repository *github.Repository) map[string][]*ExcludedCandidate {
+	excluded := make(map[string][]*ExcludedCandidate)
+	for _, configFile := range c.configurationFiles {
+		for _, value := range configFile.Values {
+			for _, repositoryConfig := range value.Repositories {
+				reason := c.exclusionReason(repositoryConfig.entry(), repository)
+				if reason == "" {
+					continue
+				}
+				excluded[configFile.PropertyName] = append(excluded[configFile.PropertyName], &ExcludedCandidate{
+					Candidate: Candidate{
+						Value:    c.candidateValue(configFile, value, repository),
+						Entry:    repositoryConfig.entry(),
+						Source:   configFile.Source,
+						Line:     value.Line,
+						Priority: configFile.Priority,
+					},
+					Reason: reason,
+				})
+			}
+		}
+	}
+	return excluded
+}
+
+// exclusionReason returns why a selector skips a repository it would otherwise
+// match, or an empty string
+func (c *Config) exclusionReason(entry string, repository *github.Repository) string {
+	if !isRepositorySelector(entry) {
+		return ""
+	}
+	selector, err := parseRepositorySelector(entry)
+	if err != nil {
+		return ""
+	}
+
+	// Team selectors match the repositories listed for the team, which carry the
+	// team's permissions
+	var listed *github.Repository
+	if selector.team != "" {
+		for _, teamRepository := range c.teamRepositoryLists[strings.ToLower(selector.organizations+"/"+selector.team)] {
+			if strings.EqualFold(fullName(teamRepository), fullName(repository)) {
+				listed = teamRepository
+			}
+		}
+	} else if slices.ContainsFunc(c.organizationLists[selector.organizations], func(organization string) bool {
+		return strings.EqualFold(organization, repository.GetOwner().GetLogin())
+	}) && selector.matches(repository) {
+		listed = repository
+	}
+	if listed == nil {
+		return ""
+	}
+
+	switch {
+	case repository.GetArchived():
+		return "archived repositories are not matched by selectors"
+	case repository.GetDisabled():
+		return "disabled repositories are not matched by selectors"
+	case !selector.permitted(listed):
+		return fmt.Sprintf("team %s/%s does not have %s permission", selector.organizations, selector.team, selector.permission)
+	}
+	return ""
+}
+
+// candidateValue returns a configured value for a repository, rendering templates
+// where possible
+func (c *Config) candidateValue(configFile *ConfigFile, value ValueConfig, repository *github.Repository) string {
+	if !configFile.isTemplateValue(value) {
+		return value.Value
+	}
+	valueTemplate, err := parseValueTemplate(value.Value)
+	if err != nil {
+		return value.Value
+	}
+	rendered, err := renderValue(valueTemplate, repository)
+	if err != nil {
+		return value.Value
+	}
+	return rendered
+}
@@ -0,0 +1,102 @@
+package config
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/google/go-github/v74/github"
+)
+
+func TestExplain(t *testing.T) {
+	mockClient := NewMockGitHubClient()
+	mockClient.AddRepository("org1", "repo1", map[string]interface{}{"team": "platform", "cost-center": "1234"})
+	mockClient.AddRepository("org1", "archived", map[string]interface{}{})
+	mockClient.repositories["org1/archived"].Archived = github.Ptr(true)
+	mockClient.AddTeamRepository("org1", "payments", "repo1", "push")
+
+	config := NewConfig(mockClient)
+	layers := []struct {
+		priority int
+		content  string
+	}{
+		{0, `property_name: "team"
+values:
+  - value: "platform"
+    repositories:
+      - name: "org1/*"
+  - value: "payments"
+    repositories:
+      - team: "org1/payments"
+        permission: "admin"
+`},
+		{0, `property_name: "lifecycle"
+values:
+  - value: "retired"
+    repositories:
+      - name: "org1/archived"
+`},
+		{10, `property_name: "team"
+values:
+  - value: "frontend"
+    repositories:
+      - name: "org1/repo1"
+`},
+	}
+	for _, layer := range layers {
+		if err := config.LoadLayer(strings.NewReader(layer.content), layer.priority); err != nil {
+			t.Fatalf("LoadLayer failed: %v", err)
+		}
+	}
+	if err := config.GenerateRepositories(context.Background()); err != nil {
+		t.Fatalf("GenerateRepositories failed: %v", err)
+	}
+
+	explanation, err := config.Explain(context.Background(), "org1/repo1", "")
+	if err != nil {
+		t.Fatalf("Explain failed: %v", err)
+	}
+	if explanation.Repository != "org1/repo1" || len(explanation.Properties) != 2 {
+		t.Fatalf("unexpected explanation %+v", explanation)
+	}
+	costCenter, team := explanation.Properties[0], explanation.Properties[1]
+	if costCenter.PropertyName != "cost-center" || costCenter.Configured || costCenter.CurrentValue != "1234" {
+		t.Errorf("expected an unconfigured cost-center, got %+v", costCenter)
+	}
+	if team.DesiredValue != "frontend" || team.CurrentValue != "platform" || team.Winner.Priority != 10 {
+		t.Errorf("expected team to be frontend from the overlay, got %+v", team)
+	}
+	if len(team.Overridden) != 1 || team.Overridden[0].Entry != "org1/*" {
+		t.Errorf("expected the selector to be overridden, got %+v", team.Overridden)
+	}
+	if len(team.Excluded) != 1 || team.Excluded[0].Value != "payments" || !strings.Contains(team.Excluded[0].Reason, "does not have admin permission") {
+		t.Errorf("expected the team entry to be excluded, got %+v", team.Excluded)
+	}
+
+	explanation, err = config.Explain(context.Background(), "org1/archived", "")
+	if err != nil {
+		t.Fatalf("Explain failed: %v", err)
+	}
+	if len(explanation.Properties) != 2 {
+		t.Fatalf("unexpected explanation %+v", explanation)
+	}
+	lifecycle, team := explanation.Properties[0], explanation.Properties[1]
+	if lifecycle.DesiredValue != "retired" || !lifecycle.Skipped || len(lifecycle.Markers) != 1 || lifecycle.Markers[0] != "archived" {
+		t.Errorf("expected a skipped change of lifecycle, got %+v", lifecycle)
+	}
+	if team.Configured || len(team.Excluded) != 1 || !strings.Contains(team.Excluded[0].Reason, "archived repositories") {
+		t.Errorf("expected the selector to skip the archived repository, got %+v", team)
+	}
+
+	explanation, err = config.Explain(context.Background(), "org1/repo1", "lifecycle")
+	if err != nil {
+		t.Fatalf("Explain failed: %v", err)
+	}
+	if len(explanation.Properties) != 1 || explanation.Properties[0].Configured || explanation.Properties[0].CurrentValue != "" {
+		t.Errorf("expected an unconfigured lifecycle, got %+v", explanation.Properties)
+	}
+
+	if _, err := config.Explain(context.Background(), "org1/missing", ""); err == nil || !strings.Contains(err.Error(), "not found") {
+		t.Errorf("expected a not found error, got %v", err)
+	}
+}
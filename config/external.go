@@ -0,0 +1,237 @@
+package config
+
+import (
+	"bufio"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// RepositoriesFrom reads the repositories of a value from an external file: a text
+// file with one repository per line, or a column of a CSV file with a header row. It
+// can be written as just the path of the file.
+type RepositoriesFrom struct {
+	File string `yaml:"file"`
+	// Column is the CSV column holding repository names, "repository" by default
+	Column string `yaml:"column,omitempty"`
+	// OrganizationColumn is an optional CSV column holding the organization, for
+	// files listing repository names without it
+	OrganizationColumn string `yaml:"organization_column,omitempty"`
+}
+
+// UnmarshalYAML accepts a plain path as well as a mapping
+func (r *RepositoriesFrom) UnmarshalYAML(unmarshal func(any) error) error {
+	var file string
+	if err := unmarshal(&file); err == nil {
+		*r = RepositoriesFrom{File: file}
+		return nil
+	}
+	type plain RepositoriesFrom
+	return unmarshal((*plain)(r))
+}
+
+// ValuesFrom reads the values of a property from a CSV file with a header row,
+// mapping each repository to its value
+type ValuesFrom struct {
+	File string `yaml:"file"`
+	// RepositoryColumn is the column holding repository names, "repository" by default
+	RepositoryColumn string `yaml:"repository_column,omitempty"`
+	// ValueColumn is the column holding values, "value" by default
+	ValueColumn string `yaml:"value_column,omitempty"`
+	// OrganizationColumn is an optional column holding the organization, for files
+	// listing repository names without it
+	OrganizationColumn string `yaml:"organization_column,omitempty"`
+	// UnsetEmpty makes empty value cells unset the property. Without it they are
+	// rejected, so that missing data never removes values.
+	UnsetEmpty bool `yaml:"unset_empty,omitempty"`
+}
+
+// externalRow is a row read from an external file
+type externalRow struct {
+	location   string
+	repository string
+	value      string
+}
+
+// loadExternalFiles adds the repositories and values read from the external files
+// referenced by a configuration file. Relative paths are resolved against the
+// directory of the configuration file. Each row is validated like an inline entry.
+// Values read from CSV files are literal and never rendered as templates.
+func (configFile *ConfigFile) loadExternalFiles() error {
+	var errs []error
+	for i := range configFile.Values {
+		value := &configFile.Values[i]
+		if value.RepositoriesFrom == nil {
+			continue
+		}
+		rows, err := readRepositories(configFile.resolvePath(value.RepositoriesFrom.File), value.RepositoriesFrom)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", valueLocation(configFile, i), err))
+			continue
+		}
+		for _, row := range rows {
+			if err := validateRepositoryName(row.repository); err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", row.location, err))
+				continue
+			}
+			value.Repositories = append(value.Repositories, RepositoryConfig{Name: row.repository})
+		}
+	}
+
+	if configFile.ValuesFrom != nil {
+		rows, err := readValues(configFile.resolvePath(configFile.ValuesFrom.File), configFile.ValuesFrom)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("values_from of property '%s': %w", configFile.PropertyName, err))
+		}
+		valueIndex := make(map[string]int)
+		for _, row := range rows {
+			if err := validateRepositoryName(row.repository); err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", row.location, err))
+				continue
+			}
+			if row.value == "" && !configFile.ValuesFrom.UnsetEmpty {
+				errs = append(errs, fmt.Errorf("%s: empty value for repository %s; set unset_empty to unset the property", row.location, row.repository))
+				continue
+			}
+			index, exists := valueIndex[row.value]
+			if !exists {
+				index = len(configFile.Values)
+				valueIndex[row.value] = index
+				configFile.Values = append(configFile.Values, ValueConfig{Value: row.value, Literal: true})
+			}
+			configFile.Values[index].Repositories = append(configFile.Values[index].Repositories, RepositoryConfig{Name: row.repository})
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// resolvePath resolves a path relative to the directory of the configuration file
+func (configFile *ConfigFile) resolvePath(path string) string {
+	if filepath.IsAbs(path) || configFile.Source == "" {
+		return path
+	}
+	return filepath.Join(filepath.Dir(configFile.Source), path)
+}
+
+// readRepositories reads repository names from a text or CSV file
+func readRepositories(path string, repositoriesFrom *RepositoriesFrom) ([]externalRow, error) {
+	if path == "" {
+		return nil, fmt.Errorf("repositories_from has no file")
+	}
+	if strings.EqualFold(filepath.Ext(path), ".csv") {
+		column := repositoriesFrom.Column
+		if column == "" {
+			column = "repository"
+		}
+		return readCSV(path, column, "", repositoriesFrom.OrganizationColumn)
+	}
+	if repositoriesFrom.Column != "" || repositoriesFrom.OrganizationColumn != "" {
+		return nil, fmt.Errorf("columns can only be mapped for CSV files, not %s", path)
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s: %w", path, err)
+	}
+	defer file.Close()
+
+	var rows []externalRow
+	scanner := bufio.NewScanner(file)
+	for line := 1; scanner.Scan(); line++ {
+		name := strings.TrimSpace(scanner.Text())
+		if name == "" || strings.HasPrefix(name, "#") {
+			continue
+		}
+		rows = append(rows, externalRow{location: fmt.Sprintf("%s:%d", path, line), repository: name})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	return rows, nil
+}
+
+// readValues reads repositories and their values from a CSV file
+func readValues(path string, valuesFrom *ValuesFrom) ([]externalRow, error) {
+	if path == "" {
+		return nil, fmt.Errorf("values_from has no file")
+	}
+	repositoryColumn, valueColumn := valuesFrom.RepositoryColumn, valuesFrom.ValueColumn
+	if repositoryColumn == "" {
+		repositoryColumn = "repository"
+	}
+	if valueColumn == "" {
+		valueColumn = "value"
+	}
+	return readCSV(path, repositoryColumn, valueColumn, valuesFrom.OrganizationColumn)
+}
+
+// readCSV reads the mapped columns of a CSV file with a header row. Empty optional
+// column names are not read.
+func readCSV(path, repositoryColumn, valueColumn, organizationColumn string) ([]externalRow, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s: %w", path, err)
+	}
+	defer file.Close()
+
+	reader := csv.NewReader(file)
+	reader.TrimLeadingSpace = true
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the header of %s: %w", path, err)
+	}
+	// Spreadsheet exports often start with a byte order mark
+	header[0] = strings.TrimPrefix(header[0], "\ufeff")
+	columnIndex := func(name string) (int, error) {
+		if name == "" {
+			return -1, nil
+		}
+		index := slices.Index(header, name)
+		if index < 0 {
+			return -1, fmt.Errorf("%s has no column %s", path, name)
+		}
+		return index, nil
+	}
+	repositoryIndex, err := columnIndex(repositoryColumn)
+	if err != nil {
+		return nil, err
+	}
+	valueIndex, err := columnIndex(valueColumn)
+	if err != nil {
+		return nil, err
+	}
+	organizationIndex, err := columnIndex(organizationColumn)
+	if err != nil {
+		return nil, err
+	}
+
+	var rows []externalRow
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", path, err)
+		}
+		line, _ := reader.FieldPos(0)
+		row := externalRow{
+			location:   fmt.Sprintf("%s:%d", path, line),
+			repository: strings.TrimSpace(record[repositoryIndex]),
+		}
+		if organizationIndex >= 0 {
+			row.repository = strings.TrimSpace(record[organizationIndex]) + "/" + row.repository
+		}
+		if valueIndex >= 0 {
+			row.value = record[valueIndex]
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
@@ -0,0 +1,186 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeFiles writes files to a temporary directory and returns its path
+func writeFiles(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o600); err != nil {
+			t.Fatalf("failed to write %s: %v", name, err)
+		}
+	}
+	return dir
+}
+
+func loadConfigFile(config *Config, path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	return config.LoadConfig(file)
+}
+
+func TestLoadConfigExternalFiles(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"team.yaml": `property_name: "team"
+values:
+  - value: "backend"
+    repositories:
+      - name: "org1/repo1"
+    repositories_from: "backend.txt"
+  - value: "frontend"
+    repositories_from:
+      file: "services.csv"
+      column: "service"
+      organization_column: "org"
+`,
+		"backend.txt":  "# Backend services\norg1/repo2\n\norg1/repo3\n",
+		"services.csv": "org,service,owner\norg1,web,alice\norg2,app,bob\n",
+		"tier.yaml": `property_name: "tier"
+values_from:
+  file: "tiers.csv"
+  repository_column: "repo"
+  value_column: "tier"
+`,
+		"tiers.csv": "repo,tier\norg1/repo1,gold\norg1/repo2,silver\norg1/web,gold\n",
+	})
+
+	mockClient := NewMockGitHubClient()
+	for _, name := range []string{"org1/repo1", "org1/repo2", "org1/repo3", "org1/web", "org2/app"} {
+		org, repo, _ := strings.Cut(name, "/")
+		mockClient.AddRepository(org, repo, nil)
+	}
+	config := NewConfig(mockClient)
+	for _, name := range []string{"team.yaml", "tier.yaml"} {
+		if err := loadConfigFile(config, filepath.Join(dir, name)); err != nil {
+			t.Fatalf("LoadConfig of %s failed: %v", name, err)
+		}
+	}
+	ctx := context.Background()
+	if err := config.GenerateRepositories(ctx); err != nil {
+		t.Fatalf("GenerateRepositories failed: %v", err)
+	}
+	diffs, err := config.GenerateDiffs(ctx)
+	if err != nil {
+		t.Fatalf("GenerateDiffs failed: %v", err)
+	}
+
+	var changes []string
+	for _, diff := range diffs {
+		changes = append(changes, fmt.Sprintf("%s/%s %s=%s", diff.Organization, diff.Repository, diff.PropertyName, diff.NewValue))
+	}
+	expected := []string{
+		"org1/repo1 team=backend",
+		"org1/repo1 tier=gold",
+		"org1/repo2 team=backend",
+		"org1/repo2 tier=silver",
+		"org1/repo3 team=backend",
+		"org1/web team=frontend",
+		"org1/web tier=gold",
+		"org2/app team=frontend",
+	}
+	if strings.Join(changes, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("unexpected diffs:\n%s", strings.Join(changes, "\n"))
+	}
+}
+
+func TestLoadConfigExternalFileErrors(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"invalid-row.yaml": `property_name: "team"
+values:
+  - value: "backend"
+    repositories_from: "backend.txt"
+`,
+		"backend.txt": "org1/repo1\nnot-a-repository\n",
+		"missing-column.yaml": `property_name: "team"
+values_from:
+  file: "teams.csv"
+`,
+		"teams.csv": "repository,owner\norg1/repo1,backend\n",
+		"conflict.yaml": `property_name: "team"
+values:
+  - value: "frontend"
+    repositories:
+      - name: "org1/repo1"
+values_from:
+  file: "conflict.csv"
+`,
+		"conflict.csv": "repository,value\norg1/repo1,backend\n",
+		"missing-file.yaml": `property_name: "team"
+values:
+  - value: "backend"
+    repositories_from: "missing.txt"
+`,
+		"empty-value.yaml": `property_name: "team"
+values_from:
+  file: "empty.csv"
+`,
+		"empty.csv": "repository,value\norg1/repo1,backend\norg1/repo2,\n",
+	})
+
+	tests := map[string]string{
+		"invalid-row.yaml":    "backend.txt:2: repository name not-a-repository is not in the format 'org/repo'",
+		"missing-column.yaml": "has no column value",
+		"conflict.yaml":       "conflicting values",
+		"missing-file.yaml":   "missing.txt",
+		"empty-value.yaml":    "empty.csv:3: empty value for repository org1/repo2; set unset_empty to unset the property",
+	}
+	for name, expected := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := loadConfigFile(NewConfig(NewMockGitHubClient()), filepath.Join(dir, name))
+			if err == nil || !strings.Contains(err.Error(), expected) {
+				t.Errorf("expected an error containing %q, got %v", expected, err)
+			}
+		})
+	}
+}
+
+func TestLoadConfigValuesFromCSV(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"team.yaml": `property_name: "team"
+values_from:
+  file: "teams.csv"
+  unset_empty: true
+`,
+		// A byte order mark, a value looking like a template and an empty value
+		"teams.csv": "\ufeffrepository,value\norg1/repo1,{{ .Name }}\norg1/repo2,\n",
+	})
+
+	mockClient := NewMockGitHubClient()
+	mockClient.AddRepository("org1", "repo1", nil)
+	mockClient.AddRepository("org1", "repo2", map[string]interface{}{"team": "backend"})
+	config := NewConfig(mockClient)
+	if err := loadConfigFile(config, filepath.Join(dir, "team.yaml")); err != nil {
+		t.Fatalf("LoadConfig failed: %v", err)
+	}
+	ctx := context.Background()
+	if err := config.GenerateRepositories(ctx); err != nil {
+		t.Fatalf("GenerateRepositories failed: %v", err)
+	}
+	diffs, err := config.GenerateDiffs(ctx)
+	if err != nil {
+		t.Fatalf("GenerateDiffs failed: %v", err)
+	}
+
+	var changes []string
+	for _, diff := range diffs {
+		changes = append(changes, fmt.Sprintf("%s/%s %s=%s", diff.Organization, diff.Repository, diff.PropertyName, diff.NewValue))
+	}
+	expected := []string{
+		"org1/repo1 team={{ .Name }}",
+		"org1/repo2 team=",
+	}
+	if strings.Join(changes, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("unexpected diffs:\n%s", strings.Join(changes, "\n"))
+	}
+}
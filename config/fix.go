@@ -0,0 +1,136 @@
+package config
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/goccy/go-yaml/ast"
+	"github.com/goccy/go-yaml/parser"
+	"github.com/goccy/go-yaml/token"
+)
+
+// FixRepositoryNames rewrites a configuration file after GenerateRepositories has
+// resolved the configured repositories. With renames, repository names are replaced
+// by the canonical names of renamed or transferred repositories. With recordIDs, the
+// repository ID is recorded next to each name so that later renames are caught
+// reliably. Comments are preserved. Names referencing variables are not rewritten;
+// a warning is added when they would be.
+func (c *Config) FixRepositoryNames(data []byte, renames, recordIDs bool) ([]byte, error) {
+	file, err := parser.ParseBytes(data, parser.ParseComments)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse config: %w", err)
+	}
+
+	if err := checkSingleDocument(file); err != nil {
+		return nil, err
+	}
+
+	canonicalNames := make(map[string]string, len(c.renames))
+	for _, rename := range c.renames {
+		canonicalNames[repositoryKey(rename.ConfiguredName)] = rename.CanonicalName
+	}
+
+	for _, doc := range file.Docs {
+		values, ok := mappingField(doc.Body, "values").(*ast.SequenceNode)
+		if !ok {
+			continue
+		}
+		for _, value := range values.Values {
+			repositories, ok := mappingField(value, "repositories").(*ast.SequenceNode)
+			if !ok {
+				continue
+			}
+			for _, repository := range repositories.Values {
+				repositoryMapping, ok := repository.(*ast.MappingNode)
+				if !ok {
+					continue
+				}
+
+				nameField := mappingValueField(repositoryMapping, "name")
+				if nameField == nil {
+					continue
+				}
+				name := scalarString(nameField.Value)
+				// Names referencing variables are resolved like when loading, but left
+				// as written: a fixed name or ID would only hold for these variables
+				resolvedName, err := c.interpolate(name)
+				if err != nil {
+					return nil, fmt.Errorf("repository %s: %w", name, err)
+				}
+				hasVariables := resolvedName != name
+
+				if renames {
+					if canonicalName, exists := canonicalNames[repositoryKey(resolvedName)]; exists {
+						if hasVariables {
+							c.addWarning("repository %s (%s) was renamed to %s; it references variables, so update it by hand", name, resolvedName, canonicalName)
+						} else {
+							nameField.Value = replaceScalar(nameField.Value, canonicalName)
+						}
+					}
+				}
+
+				if recordIDs && !isRepositorySelector(resolvedName) {
+					resolved := c.lookupRepository(resolvedName)
+					if resolved == nil || resolved.GetID() == 0 {
+						continue
+					}
+					if hasVariables {
+						c.addWarning("not recording the ID of repository %s (%s), as it references variables", name, resolvedName)
+						continue
+					}
+					id := strconv.FormatInt(resolved.GetID(), 10)
+					if idField := mappingValueField(repositoryMapping, "id"); idField != nil {
+						idField.Value = replaceScalar(idField.Value, id)
+						continue
+					}
+					idFile, err := parser.ParseBytes([]byte(fmt.Sprintf("id: %s\n", id)), 0)
+					if err != nil {
+						return nil, fmt.Errorf("failed to record id of repository %s: %w", name, err)
+					}
+					if err := ast.Merge(repositoryMapping, idFile.Docs[0]); err != nil {
+						return nil, fmt.Errorf("failed to record id of repository %s: %w", name, err)
+					}
+				}
+			}
+		}
+	}
+
+	fixed := file.String()
+	if !strings.HasSuffix(fixed, "\n") {
+		fixed += "\n"
+	}
+	return []byte(fixed), nil
+}
+
+func mappingValueField(mapping *ast.MappingNode, key string) *ast.MappingValueNode {
+	for _, mappingValue := range mapping.Values {
+		if mappingKey(mappingValue) == key {
+			return mappingValue
+		}
+	}
+	return nil
+}
+
+// replaceScalar returns a copy of a scalar node with a new value, keeping its quoting style and comment
+func replaceScalar(node ast.Node, value string) ast.Node {
+	replacedToken := *node.GetToken()
+	replacedToken.Value = value
+	replacedToken.Origin = value
+	if _, isString := node.(*ast.StringNode); !isString {
+		if _, err := strconv.ParseInt(value, 10, 64); err == nil {
+			integerNode := ast.Integer(&replacedToken)
+			if comment := node.GetComment(); comment != nil {
+				_ = integerNode.SetComment(comment)
+			}
+			return integerNode
+		}
+		replacedToken.Type = token.StringType
+	}
+
+	stringNode := ast.String(&replacedToken)
+	if comment := node.GetComment(); comment != nil {
+		_ = stringNode.SetComment(comment)
+	}
+	return stringNode
+}
@@ -0,0 +1,218 @@
+package config
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/google/go-github/v74/github"
+)
+
+func newRenamedRepositoryConfig(t *testing.T, configContent string) *Config {
+	t.Helper()
+
+	mockClient := NewMockGitHubClient()
+	// org1/old-name was renamed to org1/new-name, org1/moved was transferred to org2/moved
+	mockClient.repositories["org1/old-name"] = &github.Repository{
+		ID:    github.Ptr(int64(1)),
+		Name:  github.Ptr("new-name"),
+		Owner: &github.User{Login: github.Ptr("org1")},
+	}
+	mockClient.repositories["org1/moved"] = &github.Repository{
+		ID:    github.Ptr(int64(2)),
+		Name:  github.Ptr("moved"),
+		Owner: &github.User{Login: github.Ptr("org2")},
+	}
+	mockClient.repositories["org1/stable"] = &github.Repository{
+		ID:    github.Ptr(int64(3)),
+		Name:  github.Ptr("stable"),
+		Owner: &github.User{Login: github.Ptr("org1")},
+	}
+
+	config := NewConfig(mockClient)
+	if err := config.LoadConfig(strings.NewReader(configContent)); err != nil {
+		t.Fatalf("LoadConfig failed: %v", err)
+	}
+	if err := config.GenerateRepositories(context.Background()); err != nil {
+		t.Fatalf("GenerateRepositories failed: %v", err)
+	}
+	return config
+}
+
+func TestGenerateRepositoriesDetectsRenames(t *testing.T) {
+	config := newRenamedRepositoryConfig(t, `property_name: "team"
+values:
+  - value: "backend"
+    repositories:
+      - name: "org1/old-name"
+      - name: "org1/moved"
+      - name: "org1/stable"`)
+
+	renames := config.Renames()
+	if len(renames) != 2 {
+		t.Fatalf("expected 2 renames, got %d", len(renames))
+	}
+	if renames[0].ConfiguredName != "org1/old-name" || renames[0].CanonicalName != "org1/new-name" || renames[0].ID != 1 {
+		t.Errorf("unexpected rename: %+v", renames[0])
+	}
+	if renames[1].ConfiguredName != "org1/moved" || renames[1].CanonicalName != "org2/moved" {
+		t.Errorf("unexpected rename: %+v", renames[1])
+	}
+
+	warnings := strings.Join(config.Warnings(), "\n")
+	if !strings.Contains(warnings, "repository org1/old-name has been renamed or transferred to org1/new-name") {
+		t.Errorf("expected rename warning, got %q", warnings)
+	}
+
+	// Renamed repositories must still produce diffs
+	diffs, err := config.GenerateDiffs(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(diffs) != 3 {
+		t.Fatalf("expected 3 diffs, got %d", len(diffs))
+	}
+	if diffs[0].Organization != "org1" || diffs[0].Repository != "new-name" {
+		t.Errorf("expected first diff for org1/new-name, got %s/%s", diffs[0].Organization, diffs[0].Repository)
+	}
+}
+
+func TestGenerateRepositoriesByID(t *testing.T) {
+	config := newRenamedRepositoryConfig(t, `property_name: "team"
+values:
+  - value: "backend"
+    repositories:
+      - name: "org1/previous-name"
+        id: 3`)
+
+	if len(config.repositories) != 1 || config.repositories[0].GetName() != "stable" {
+		t.Fatalf("expected repository to be resolved by id, got %v", config.repositories)
+	}
+	renames := config.Renames()
+	if len(renames) != 1 || renames[0].CanonicalName != "org1/stable" {
+		t.Errorf("expected rename to org1/stable, got %v", renames)
+	}
+}
+
+func TestFixRepositoryNames(t *testing.T) {
+	configContent := `property_name: "team"
+values:
+  - value: "backend"
+    repositories:
+      # renamed last year
+      - name: "org1/old-name" # keep me
+      - name: "org1/moved"
+      - name: "org1/stable"
+`
+	config := newRenamedRepositoryConfig(t, configContent)
+
+	tests := []struct {
+		name      string
+		renames   bool
+		recordIDs bool
+		expected  string
+	}{
+		{
+			name:    "renames",
+			renames: true,
+			expected: `property_name: "team"
+values:
+  - value: "backend"
+    repositories:
+      # renamed last year
+      - name: "org1/new-name" # keep me
+      - name: "org2/moved"
+      - name: "org1/stable"
+`,
+		},
+		{
+			name:      "renames and ids",
+			renames:   true,
+			recordIDs: true,
+			expected: `property_name: "team"
+values:
+  - value: "backend"
+    repositories:
+      # renamed last year
+      - name: "org1/new-name" # keep me
+        id: 1
+      - name: "org2/moved"
+        id: 2
+      - name: "org1/stable"
+        id: 3
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fixed, err := config.FixRepositoryNames([]byte(configContent), tt.renames, tt.recordIDs)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if string(fixed) != tt.expected {
+				t.Errorf("unexpected output:\n%s\nexpected:\n%s", fixed, tt.expected)
+			}
+		})
+	}
+}
+
+func TestFixRepositoryNamesWithVariables(t *testing.T) {
+	t.Setenv("ORG", "org1")
+	configContent := `property_name: "team"
+values:
+  - value: "backend"
+    repositories:
+      - name: "${ORG}/old-name"
+      - name: "${ORG}/stable"
+`
+	config := newRenamedRepositoryConfig(t, configContent)
+
+	fixed, err := config.FixRepositoryNames([]byte(configContent), true, true)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if string(fixed) != configContent {
+		t.Errorf("names referencing variables should be left as written, got:\n%s", fixed)
+	}
+	expected := []string{
+		"repository ${ORG}/old-name (org1/old-name) was renamed to org1/new-name; it references variables, so update it by hand",
+		"not recording the ID of repository ${ORG}/old-name (org1/old-name), as it references variables",
+		"not recording the ID of repository ${ORG}/stable (org1/stable), as it references variables",
+	}
+	warnings := config.Warnings()
+	for _, warning := range expected {
+		if !slices.Contains(warnings, warning) {
+			t.Errorf("missing warning %q in %v", warning, warnings)
+		}
+	}
+}
+
+func TestMultipleDocumentsRejected(t *testing.T) {
+	configContent := `property_name: "team"
+values:
+  - value: "backend"
+    repositories:
+      - name: "org1/stable"
+---
+property_name: "tier"
+values:
+  - value: "1"
+    repositories:
+      - name: "org1/moved"
+`
+	if err := NewConfig(NewMockGitHubClient()).LoadConfig(strings.NewReader(configContent)); err == nil || !strings.Contains(err.Error(), "found 2 YAML documents") {
+		t.Errorf("expected LoadConfig to reject several documents, got %v", err)
+	}
+
+	config := newRenamedRepositoryConfig(t, `property_name: "team"
+values:
+  - value: "backend"
+    repositories:
+      - name: "org1/stable"
+`)
+	if _, err := config.FixRepositoryNames([]byte(configContent), true, true); err == nil || !strings.Contains(err.Error(), "found 2 YAML documents") {
+		t.Errorf("expected FixRepositoryNames to reject several documents, got %v", err)
+	}
+}
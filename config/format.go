@@ -0,0 +1,228 @@
+package config
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/goccy/go-yaml"
+	"github.com/goccy/go-yaml/ast"
+	"github.com/goccy/go-yaml/parser"
+	"github.com/goccy/go-yaml/token"
+)
+
+// Format rewrites a configuration file into canonical form. Values are sorted,
+// repositories are sorted and de-duplicated, and all strings are double-quoted.
+// Comments are preserved.
+func Format(data []byte) ([]byte, error) {
+	var configFile ConfigFile
+	if err := yaml.UnmarshalWithOptions(data, &configFile, yaml.DisallowUnknownField()); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal config: %w", err)
+	}
+
+	file, err := parser.ParseBytes(data, parser.ParseComments)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse config: %w", err)
+	}
+
+	for _, doc := range file.Docs {
+		body, ok := doc.Body.(*ast.MappingNode)
+		if !ok {
+			continue
+		}
+		formatConfigFile(body)
+	}
+
+	formatted := file.String()
+	if !strings.HasSuffix(formatted, "\n") {
+		formatted += "\n"
+	}
+	return []byte(formatted), nil
+}
+
+func formatConfigFile(body *ast.MappingNode) {
+	for _, mappingValue := range body.Values {
+		switch mappingKey(mappingValue) {
+		case "property_name":
+			mappingValue.Value = quoteScalar(mappingValue.Value)
+		case "values":
+			values, ok := mappingValue.Value.(*ast.SequenceNode)
+			if !ok {
+				continue
+			}
+			for _, value := range values.Values {
+				valueMapping, ok := value.(*ast.MappingNode)
+				if !ok {
+					continue
+				}
+				formatValueConfig(valueMapping)
+			}
+			sortSequence(values, func(node ast.Node) string {
+				return scalarString(mappingField(node, "value"))
+			})
+		}
+	}
+}
+
+func formatValueConfig(valueMapping *ast.MappingNode) {
+	for _, mappingValue := range valueMapping.Values {
+		switch mappingKey(mappingValue) {
+		case "value":
+			mappingValue.Value = quoteScalar(mappingValue.Value)
+		case "repositories":
+			repositories, ok := mappingValue.Value.(*ast.SequenceNode)
+			if !ok {
+				continue
+			}
+			for _, repository := range repositories.Values {
+				repositoryMapping, ok := repository.(*ast.MappingNode)
+				if !ok {
+					continue
+				}
+				for _, field := range repositoryMapping.Values {
+					switch mappingKey(field) {
+					case "name", "team", "permission":
+						field.Value = quoteScalar(field.Value)
+					}
+				}
+			}
+			// Repository names are compared case-insensitively like on GitHub
+			repositoryName := func(node ast.Node) string {
+				return repositoryKey(RepositoryConfig{
+					Name:       scalarString(mappingField(node, "name")),
+					Team:       scalarString(mappingField(node, "team")),
+					Permission: scalarString(mappingField(node, "permission")),
+				}.entry())
+			}
+			dedupeSequence(repositories, repositoryName)
+			sortSequence(repositories, repositoryName)
+		}
+	}
+}
+
+func mappingKey(mappingValue *ast.MappingValueNode) string {
+	return scalarString(mappingValue.Key)
+}
+
+// mappingField returns the value of the given key if node is a mapping
+func mappingField(node ast.Node, key string) ast.Node {
+	mapping, ok := node.(*ast.MappingNode)
+	if !ok {
+		return nil
+	}
+	for _, mappingValue := range mapping.Values {
+		if mappingKey(mappingValue) == key {
+			return mappingValue.Value
+		}
+	}
+	return nil
+}
+
+func scalarString(node ast.Node) string {
+	switch n := node.(type) {
+	case nil:
+		return ""
+	case *ast.StringNode:
+		return n.Value
+	case ast.ScalarNode:
+		return fmt.Sprint(n.GetValue())
+	default:
+		return node.String()
+	}
+}
+
+// quoteScalar converts a scalar node into a double-quoted string node
+func quoteScalar(node ast.Node) ast.Node {
+	scalar, ok := node.(ast.ScalarNode)
+	if !ok || node.Type() == ast.LiteralType {
+		return node
+	}
+
+	quotedToken := *node.GetToken()
+	quotedToken.Type = token.DoubleQuoteType
+	quotedToken.Value = scalarString(scalar)
+	if _, isNull := node.(*ast.NullNode); isNull {
+		quotedToken.Value = ""
+	}
+
+	stringNode := ast.String(&quotedToken)
+	if comment := node.GetComment(); comment != nil {
+		if err := stringNode.SetComment(comment); err != nil {
+			return node
+		}
+	}
+	return stringNode
+}
+
+// normalizeHeadComments moves the head comment of the first entry, which the parser
+// attaches to the sequence itself, next to the other entry head comments.
+func normalizeHeadComments(sequence *ast.SequenceNode) {
+	if len(sequence.ValueHeadComments) != len(sequence.Values) {
+		headComments := make([]*ast.CommentGroupNode, len(sequence.Values))
+		copy(headComments, sequence.ValueHeadComments)
+		sequence.ValueHeadComments = headComments
+	}
+	if len(sequence.Values) > 0 && sequence.Comment != nil && sequence.ValueHeadComments[0] == nil {
+		sequence.ValueHeadComments[0] = sequence.Comment
+		sequence.Comment = nil
+	}
+	if len(sequence.Entries) != len(sequence.Values) {
+		sequence.Entries = nil
+	}
+}
+
+type sequenceItem struct {
+	value       ast.Node
+	headComment *ast.CommentGroupNode
+	entry       *ast.SequenceEntryNode
+}
+
+func sequenceItems(sequence *ast.SequenceNode) []sequenceItem {
+	normalizeHeadComments(sequence)
+	items := make([]sequenceItem, len(sequence.Values))
+	for i, value := range sequence.Values {
+		items[i] = sequenceItem{value: value, headComment: sequence.ValueHeadComments[i]}
+		if sequence.Entries != nil {
+			items[i].entry = sequence.Entries[i]
+		}
+	}
+	return items
+}
+
+func setSequenceItems(sequence *ast.SequenceNode, items []sequenceItem) {
+	sequence.Values = make([]ast.Node, len(items))
+	sequence.ValueHeadComments = make([]*ast.CommentGroupNode, len(items))
+	if sequence.Entries != nil {
+		sequence.Entries = make([]*ast.SequenceEntryNode, len(items))
+	}
+	for i, item := range items {
+		sequence.Values[i] = item.value
+		sequence.ValueHeadComments[i] = item.headComment
+		if sequence.Entries != nil {
+			sequence.Entries[i] = item.entry
+		}
+	}
+}
+
+func sortSequence(sequence *ast.SequenceNode, key func(ast.Node) string) {
+	items := sequenceItems(sequence)
+	slices.SortStableFunc(items, func(a, b sequenceItem) int {
+		return strings.Compare(key(a.value), key(b.value))
+	})
+	setSequenceItems(sequence, items)
+}
+
+func dedupeSequence(sequence *ast.SequenceNode, key func(ast.Node) string) {
+	items := sequenceItems(sequence)
+	seen := make(map[string]bool, len(items))
+	deduped := items[:0]
+	for _, item := range items {
+		k := key(item.value)
+		if seen[k] {
+			continue
+		}
+		seen[k] = true
+		deduped = append(deduped, item)
+	}
+	setSequenceItems(sequence, deduped)
+}
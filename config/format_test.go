@@ -0,0 +1,166 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestFormat(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{
+			name: "already canonical",
+			input: `property_name: "team"
+values:
+  - value: "backend"
+    repositories:
+      - name: "org1/repo1"
+`,
+			expected: `property_name: "team"
+values:
+  - value: "backend"
+    repositories:
+      - name: "org1/repo1"
+`,
+		},
+		{
+			name: "sort values and repositories",
+			input: `property_name: team
+values:
+  - value: frontend
+    repositories:
+      - name: org1/repo2
+      - name: org1/repo1
+  - value: backend
+    repositories:
+      - name: org2/repo1
+`,
+			expected: `property_name: "team"
+values:
+  - value: "backend"
+    repositories:
+      - name: "org2/repo1"
+  - value: "frontend"
+    repositories:
+      - name: "org1/repo1"
+      - name: "org1/repo2"
+`,
+		},
+		{
+			name: "de-duplicate repositories",
+			input: `property_name: "team"
+values:
+  - value: "backend"
+    repositories:
+      - name: "org1/repo1"
+      - name: org1/repo1
+`,
+			expected: `property_name: "team"
+values:
+  - value: "backend"
+    repositories:
+      - name: "org1/repo1"
+`,
+		},
+		{
+			name: "de-duplicate repositories case-insensitively",
+			input: `property_name: "team"
+values:
+  - value: "backend"
+    repositories:
+      - name: "org1/repo2"
+      - name: "Org1/Repo1"
+      - name: "org1/repo1"
+`,
+			expected: `property_name: "team"
+values:
+  - value: "backend"
+    repositories:
+      - name: "Org1/Repo1"
+      - name: "org1/repo2"
+`,
+		},
+		{
+			name: "consistent quoting",
+			input: `property_name: 'public'
+values:
+  - value: true
+    repositories:
+      - name: 'org1/repo1'
+  - value: "false"
+    repositories:
+      - name: org1/repo2
+`,
+			expected: `property_name: "public"
+values:
+  - value: "false"
+    repositories:
+      - name: "org1/repo2"
+  - value: "true"
+    repositories:
+      - name: "org1/repo1"
+`,
+		},
+		{
+			name: "preserve comments",
+			input: `# Team ownership
+property_name: team
+values:
+  # frontend team
+  - value: frontend
+    repositories:
+      - name: org1/web # main site
+  - value: backend
+    repositories:
+      # core services
+      - name: org1/api
+`,
+			expected: `# Team ownership
+property_name: "team"
+values:
+  - value: "backend"
+    repositories:
+      # core services
+      - name: "org1/api"
+  # frontend team
+  - value: "frontend"
+    repositories:
+      - name: "org1/web" # main site
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			formatted, err := Format([]byte(tt.input))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if string(formatted) != tt.expected {
+				t.Errorf("unexpected output:\n%s\nexpected:\n%s", formatted, tt.expected)
+			}
+
+			// Formatting must be idempotent
+			reformatted, err := Format(formatted)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if string(reformatted) != string(formatted) {
+				t.Errorf("formatting is not idempotent:\n%s", reformatted)
+			}
+		})
+	}
+}
+
+func TestFormatInvalidConfig(t *testing.T) {
+	_, err := Format([]byte("property_name: team\nunknown: field\n"))
+	if err == nil {
+		t.Fatal("expected error but got none")
+	}
+	if !strings.Contains(err.Error(), "failed to unmarshal config") {
+		t.Errorf("expected error to contain 'failed to unmarshal config', got %q", err.Error())
+	}
+}
@@ -0,0 +1,49 @@
+package config
+
+// Candidate is a configured value considered for a property of a repository
+type Candidate struct {
+	Value string `json:"value"`
+	// Entry is the repository entry matching the repository: its name, a selector or
+	// 'team:org/team-slug'
+	Entry    string `json:"entry"`
+	Source   string `json:"config_file,omitempty"`
+	Line     int    `json:"line,omitempty"`
+	Priority int    `json:"priority"`
+}
+
+// Explanation describes how the desired value of a property was chosen
+type Explanation struct {
+	Winner *Candidate `json:"winner"`
+	// Overridden are candidates of lower priority or specificity
+	Overridden []*Candidate `json:"overridden,omitempty"`
+}
+
+// overrides reports whether a value takes precedence over another one: values of
+// layers with a higher priority win, then more specific repository entries
+func (d *desiredValue) overrides(other *desiredValue) bool {
+	if d.priority != other.priority {
+		return d.priority > other.priority
+	}
+	return d.specificity > other.specificity
+}
+
+func (d *desiredValue) candidate() *Candidate {
+	return &Candidate{
+		Value:    d.value,
+		Entry:    d.entry,
+		Source:   d.source,
+		Line:     d.line,
+		Priority: d.priority,
+	}
+}
+
+func (d *desiredValue) explanation() *Explanation {
+	if d.removal {
+		return nil
+	}
+	explanation := &Explanation{Winner: d.candidate()}
+	for _, overridden := range d.overridden {
+		explanation.Overridden = append(explanation.Overridden, overridden.candidate())
+	}
+	return explanation
+}
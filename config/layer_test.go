@@ -0,0 +1,96 @@
+package config
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestLoadLayerOverrides(t *testing.T) {
+	client := NewMockGitHubClient()
+	client.AddRepository("org1", "repo1", map[string]interface{}{})
+	client.AddRepository("org1", "repo2", map[string]interface{}{})
+
+	config := NewConfig(client)
+	layers := []struct {
+		priority int
+		content  string
+	}{
+		{0, `property_name: "team"
+values:
+  - value: "platform"
+    repositories:
+      - name: "org1/*"
+  - value: "backend"
+    repositories:
+      - name: "org1/repo2"
+`},
+		{10, `property_name: "team"
+values:
+  - value: "frontend"
+    repositories:
+      - name: "org1/repo1"
+  - value: "infrastructure"
+    repositories:
+      - name: "org1/*"
+`},
+	}
+	for _, layer := range layers {
+		if err := config.LoadLayer(strings.NewReader(layer.content), layer.priority); err != nil {
+			t.Fatalf("LoadLayer failed: %v", err)
+		}
+	}
+	if err := config.GenerateRepositories(context.Background()); err != nil {
+		t.Fatalf("GenerateRepositories failed: %v", err)
+	}
+	diffs, err := config.GenerateDiffs(context.Background())
+	if err != nil {
+		t.Fatalf("GenerateDiffs failed: %v", err)
+	}
+	if len(diffs) != 2 {
+		t.Fatalf("expected 2 diffs, got %d", len(diffs))
+	}
+
+	// The overlay's repository name wins over its own selector and over the base layer
+	repo1 := diffs[0]
+	if repo1.Repository != "repo1" || repo1.NewValue != "frontend" {
+		t.Errorf("expected repo1 to be set to frontend, got %+v", repo1)
+	}
+	if repo1.Explanation == nil || repo1.Explanation.Winner.Priority != 10 || repo1.Explanation.Winner.Entry != "org1/repo1" {
+		t.Fatalf("unexpected explanation %+v", repo1.Explanation)
+	}
+	if len(repo1.Explanation.Overridden) != 2 {
+		t.Errorf("expected 2 overridden candidates, got %+v", repo1.Explanation.Overridden)
+	}
+
+	// A higher priority wins over a more specific entry of a lower layer
+	repo2 := diffs[1]
+	if repo2.Repository != "repo2" || repo2.NewValue != "infrastructure" {
+		t.Errorf("expected repo2 to be set to infrastructure, got %+v", repo2)
+	}
+	if repo2.Explanation == nil || repo2.Explanation.Winner.Line != 6 || len(repo2.Explanation.Overridden) != 2 {
+		t.Errorf("unexpected explanation %+v", repo2.Explanation)
+	}
+}
+
+func TestLoadLayerSamePriorityConflict(t *testing.T) {
+	config := NewConfig(NewMockGitHubClient())
+	if err := config.LoadLayer(strings.NewReader(`property_name: "team"
+values:
+  - value: "backend"
+    repositories:
+      - name: "org1/repo1"
+`), 5); err != nil {
+		t.Fatalf("LoadLayer failed: %v", err)
+	}
+
+	err := config.LoadLayer(strings.NewReader(`property_name: "team"
+values:
+  - value: "frontend"
+    repositories:
+      - name: "org1/repo1"
+`), 5)
+	if err == nil || !strings.Contains(err.Error(), "is already configured with value") {
+		t.Errorf("expected a conflict within the layer, got %v", err)
+	}
+}
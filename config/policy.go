@@ -0,0 +1,98 @@
+package config
+
+import (
+	"fmt"
+
+	"github.com/google/go-github/v74/github"
+)
+
+// RepositoryPolicy defines how changes to archived, disabled and template repositories are handled
+type RepositoryPolicy string
+
+const (
+	// RepositoryPolicySkip plans the change but does not apply it
+	RepositoryPolicySkip RepositoryPolicy = "skip"
+	// RepositoryPolicyWarn plans and applies the change with a warning
+	RepositoryPolicyWarn RepositoryPolicy = "warn"
+	// RepositoryPolicyError fails planning
+	RepositoryPolicyError RepositoryPolicy = "error"
+)
+
+// RepositoryPolicies holds the policy for each kind of repository that may not accept changes
+type RepositoryPolicies struct {
+	Archived RepositoryPolicy
+	Disabled RepositoryPolicy
+	Template RepositoryPolicy
+}
+
+// DefaultRepositoryPolicies skips changes to archived and disabled repositories, which
+// reject updates, and warns on template repositories. Failing on them is opt-in.
+func DefaultRepositoryPolicies() RepositoryPolicies {
+	return RepositoryPolicies{
+		Archived: RepositoryPolicySkip,
+		Disabled: RepositoryPolicySkip,
+		Template: RepositoryPolicyWarn,
+	}
+}
+
+// ParseRepositoryPolicy parses a policy name
+func ParseRepositoryPolicy(s string) (RepositoryPolicy, error) {
+	switch policy := RepositoryPolicy(s); policy {
+	case RepositoryPolicySkip, RepositoryPolicyWarn, RepositoryPolicyError:
+		return policy, nil
+	default:
+		return "", fmt.Errorf("invalid repository policy '%s' (expected skip, warn or error)", s)
+	}
+}
+
+// Validate checks that every policy is known
+func (p RepositoryPolicies) Validate() error {
+	for _, policy := range []RepositoryPolicy{p.Archived, p.Disabled, p.Template} {
+		if _, err := ParseRepositoryPolicy(string(policy)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SetRepositoryPolicies sets how GenerateDiffs handles archived, disabled and template repositories
+func (c *Config) SetRepositoryPolicies(policies RepositoryPolicies) error {
+	if err := policies.Validate(); err != nil {
+		return err
+	}
+	c.repositoryPolicies = policies
+	return nil
+}
+
+// applyRepositoryPolicies marks or rejects a diff for a repository that may not accept changes
+func (c *Config) applyRepositoryPolicies(repository *github.Repository, propertyDiff *PropertyDiff) error {
+	states := []struct {
+		marker string
+		active bool
+		policy RepositoryPolicy
+	}{
+		{"archived", repository.GetArchived(), c.repositoryPolicies.Archived},
+		{"disabled", repository.GetDisabled(), c.repositoryPolicies.Disabled},
+		{"template", repository.GetIsTemplate(), c.repositoryPolicies.Template},
+	}
+
+	for _, state := range states {
+		if !state.active {
+			continue
+		}
+
+		switch state.policy {
+		case RepositoryPolicySkip:
+			propertyDiff.Markers = append(propertyDiff.Markers, state.marker)
+			propertyDiff.Skipped = true
+		case RepositoryPolicyWarn:
+			propertyDiff.Markers = append(propertyDiff.Markers, state.marker)
+			c.addWarning("repository %s is %s", fullName(repository), state.marker)
+		default:
+			return fmt.Errorf("repository %s is %s; set the %s policy to 'skip' or 'warn' to plan changes for it",
+				fullName(repository), state.marker, state.marker)
+		}
+	}
+
+	return nil
+}
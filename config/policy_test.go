@@ -0,0 +1,149 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/google/go-github/v74/github"
+)
+
+func TestParseRepositoryPolicy(t *testing.T) {
+	for _, valid := range []string{"skip", "warn", "error"} {
+		if _, err := ParseRepositoryPolicy(valid); err != nil {
+			t.Errorf("unexpected error for %q: %v", valid, err)
+		}
+	}
+	if _, err := ParseRepositoryPolicy("ignore"); err == nil {
+		t.Error("expected error for unknown policy")
+	}
+}
+
+func TestSetRepositoryPoliciesInvalid(t *testing.T) {
+	config := NewConfig(NewMockGitHubClient())
+	policies := DefaultRepositoryPolicies()
+	policies.Template = "ignore"
+
+	if err := config.SetRepositoryPolicies(policies); err == nil {
+		t.Error("expected error for invalid template policy")
+	}
+}
+
+func TestGenerateDiffsRepositoryPolicies(t *testing.T) {
+	tests := []struct {
+		name            string
+		repository      *github.Repository
+		policies        RepositoryPolicies
+		expectError     bool
+		errorContains   string
+		expectedMarkers []string
+		expectedSkipped bool
+		expectWarning   bool
+	}{
+		{
+			name:            "archived with default policy",
+			repository:      &github.Repository{Archived: github.Ptr(true)},
+			policies:        DefaultRepositoryPolicies(),
+			expectedMarkers: []string{"archived"},
+			expectedSkipped: true,
+		},
+		{
+			name:            "disabled with default policy",
+			repository:      &github.Repository{Disabled: github.Ptr(true)},
+			policies:        DefaultRepositoryPolicies(),
+			expectedMarkers: []string{"disabled"},
+			expectedSkipped: true,
+		},
+		{
+			name:          "archived with error policy",
+			repository:    &github.Repository{Archived: github.Ptr(true)},
+			policies:      RepositoryPolicies{Archived: RepositoryPolicyError, Disabled: RepositoryPolicySkip, Template: RepositoryPolicyWarn},
+			expectError:   true,
+			errorContains: "repository org1/repo1 is archived",
+		},
+		{
+			name:            "disabled with warn policy",
+			repository:      &github.Repository{Disabled: github.Ptr(true)},
+			policies:        RepositoryPolicies{Archived: RepositoryPolicyError, Disabled: RepositoryPolicyWarn, Template: RepositoryPolicyError},
+			expectedMarkers: []string{"disabled"},
+			expectWarning:   true,
+		},
+		{
+			name:            "template with default policy",
+			repository:      &github.Repository{IsTemplate: github.Ptr(true)},
+			policies:        DefaultRepositoryPolicies(),
+			expectedMarkers: []string{"template"},
+			expectWarning:   true,
+		},
+		{
+			name:       "active repository",
+			repository: &github.Repository{},
+			policies:   DefaultRepositoryPolicies(),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := NewConfig(NewMockGitHubClient())
+			if err := config.SetRepositoryPolicies(tt.policies); err != nil {
+				t.Fatalf("SetRepositoryPolicies failed: %v", err)
+			}
+			if err := config.LoadConfig(strings.NewReader(`property_name: "team"
+values:
+  - value: "backend"
+    repositories:
+      - name: "org1/repo1"`)); err != nil {
+				t.Fatalf("LoadConfig failed: %v", err)
+			}
+
+			tt.repository.Name = github.Ptr("repo1")
+			tt.repository.Owner = &github.User{Login: github.Ptr("org1")}
+			config.repositories = []*github.Repository{tt.repository}
+
+			diffs, err := config.GenerateDiffs(context.Background())
+
+			if tt.expectError {
+				if err == nil {
+					t.Fatal("expected error but got none")
+				}
+				if !strings.Contains(err.Error(), tt.errorContains) {
+					t.Errorf("expected error to contain %q, got %q", tt.errorContains, err.Error())
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(diffs) != 1 {
+				t.Fatalf("expected 1 diff, got %d", len(diffs))
+			}
+			if strings.Join(diffs[0].Markers, ",") != strings.Join(tt.expectedMarkers, ",") {
+				t.Errorf("expected markers %v, got %v", tt.expectedMarkers, diffs[0].Markers)
+			}
+			if diffs[0].Skipped != tt.expectedSkipped {
+				t.Errorf("expected skipped %v, got %v", tt.expectedSkipped, diffs[0].Skipped)
+			}
+			if (len(config.Warnings()) > 0) != tt.expectWarning {
+				t.Errorf("unexpected warnings: %v", config.Warnings())
+			}
+		})
+	}
+}
+
+func TestApplyChangeSkipped(t *testing.T) {
+	mockClient := NewMockGitHubClient()
+	mockClient.SetUpdateError(fmt.Errorf("API error"))
+	config := NewConfig(mockClient)
+
+	err := config.ApplyChange(context.Background(), &PropertyDiff{
+		Organization: "org1",
+		Repository:   "repo1",
+		PropertyName: "team",
+		NewValue:     "backend",
+		Skipped:      true,
+	})
+	if err != nil {
+		t.Errorf("skipped diff should not be applied, got error: %v", err)
+	}
+}
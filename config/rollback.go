@@ -0,0 +1,146 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"time"
+)
+
+// Plan is a saved list of changes, as shown by the plan command
+type Plan struct {
+	CreatedAt time.Time       `json:"created_at"`
+	Changes   []*PropertyDiff `json:"changes"`
+}
+
+// WritePlan writes the changes as a saved plan
+func WritePlan(w io.Writer, propertyDiffs []*PropertyDiff, createdAt time.Time) error {
+	plan := &Plan{CreatedAt: createdAt.UTC(), Changes: propertyDiffs}
+	if plan.Changes == nil {
+		plan.Changes = []*PropertyDiff{}
+	}
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(plan); err != nil {
+		return fmt.Errorf("failed to write plan: %w", err)
+	}
+	return nil
+}
+
+// ReadPlan reads a plan written by WritePlan
+func ReadPlan(r io.Reader) (*Plan, error) {
+	var plan Plan
+	if err := json.NewDecoder(r).Decode(&plan); err != nil {
+		return nil, fmt.Errorf("failed to parse plan: %w", err)
+	}
+	if plan.Changes == nil {
+		return nil, fmt.Errorf("plan has no changes field")
+	}
+	return &plan, nil
+}
+
+// Rollback reverts previously applied changes. The changes are turned into
+// configuration files setting the old values again, so that the inverse changes go
+// through the same repository resolution and policies as any other configuration.
+type Rollback struct {
+	changes []*PropertyDiff
+}
+
+// NewRollback creates a rollback of the applied changes, given in the order they were
+// applied. Skipped changes are ignored. When a property of a repository was changed
+// several times, it is reverted to the value before the first change.
+func NewRollback(applied []*PropertyDiff) *Rollback {
+	rollback := &Rollback{}
+	index := make(map[string]int)
+	for _, diff := range applied {
+		if diff.Skipped {
+			continue
+		}
+		key := rollbackKey(diff.Organization+"/"+diff.Repository, diff.PropertyName)
+		if i, exists := index[key]; exists {
+			rollback.changes[i].NewValue = diff.NewValue
+			continue
+		}
+		index[key] = len(rollback.changes)
+		change := *diff
+		rollback.changes = append(rollback.changes, &change)
+	}
+	return rollback
+}
+
+func rollbackKey(repositoryName, propertyName string) string {
+	return repositoryKey(repositoryName) + "\x00" + propertyName
+}
+
+// ConfigFiles returns configuration files setting the values from before the changes,
+// sorted by property name
+func (r *Rollback) ConfigFiles() []*ConfigFile {
+	configFiles := make(map[string]*ConfigFile)
+	for _, change := range r.changes {
+		configFile, exists := configFiles[change.PropertyName]
+		if !exists {
+			configFile = &ConfigFile{PropertyName: change.PropertyName, Literal: true}
+			configFiles[change.PropertyName] = configFile
+		}
+
+		index := -1
+		for i, value := range configFile.Values {
+			if value.Value == change.OldValue {
+				index = i
+				break
+			}
+		}
+		if index < 0 {
+			index = len(configFile.Values)
+			configFile.Values = append(configFile.Values, ValueConfig{Value: change.OldValue})
+		}
+		configFile.Values[index].Repositories = append(configFile.Values[index].Repositories, RepositoryConfig{
+			Name: change.Organization + "/" + change.Repository,
+		})
+	}
+
+	propertyNames := make([]string, 0, len(configFiles))
+	for propertyName := range configFiles {
+		propertyNames = append(propertyNames, propertyName)
+	}
+	sort.Strings(propertyNames)
+
+	result := make([]*ConfigFile, 0, len(propertyNames))
+	for _, propertyName := range propertyNames {
+		result = append(result, configFiles[propertyName])
+	}
+	return result
+}
+
+// Filter drops the diffs of repositories whose value was changed again after the
+// changes being rolled back, and returns them as warnings. Renames are the renames
+// found while resolving the configuration files, so that repositories renamed since
+// are still matched to their changes.
+func (r *Rollback) Filter(propertyDiffs []*PropertyDiff, renames []*RepositoryRename) ([]*PropertyDiff, []string) {
+	canonicalNames := make(map[string]string, len(renames))
+	for _, rename := range renames {
+		canonicalNames[repositoryKey(rename.ConfiguredName)] = rename.CanonicalName
+	}
+
+	expectedValues := make(map[string]string, len(r.changes))
+	for _, change := range r.changes {
+		name := change.Organization + "/" + change.Repository
+		if canonicalName, renamed := canonicalNames[repositoryKey(name)]; renamed {
+			name = canonicalName
+		}
+		expectedValues[rollbackKey(name, change.PropertyName)] = change.NewValue
+	}
+
+	var kept []*PropertyDiff
+	var warnings []string
+	for _, diff := range propertyDiffs {
+		expected, exists := expectedValues[rollbackKey(diff.Organization+"/"+diff.Repository, diff.PropertyName)]
+		if !exists || diff.OldValue != expected {
+			warnings = append(warnings, fmt.Sprintf("skipping %s/%s: %s was changed to %q since", diff.Organization, diff.Repository, diff.PropertyName, diff.OldValue))
+			continue
+		}
+		kept = append(kept, diff)
+	}
+	return kept, warnings
+}
@@ -0,0 +1,81 @@
+package config
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRollback(t *testing.T) {
+	mockClient := NewMockGitHubClient()
+	mockClient.AddRepository("org1", "repo1", map[string]interface{}{"team": "backend"})
+	mockClient.AddRepository("org1", "repo2", map[string]interface{}{"team": "backend"})
+	mockClient.AddRepository("org1", "repo3", map[string]interface{}{"team": "platform"})
+	mockClient.AddRepository("org1", "repo4", map[string]interface{}{"team": "frontend"})
+
+	rollback := NewRollback([]*PropertyDiff{
+		{Organization: "org1", Repository: "repo1", PropertyName: "team", OldValue: "frontend", NewValue: "infra"},
+		{Organization: "org1", Repository: "repo1", PropertyName: "team", OldValue: "infra", NewValue: "backend"},
+		{Organization: "org1", Repository: "repo2", PropertyName: "team", OldValue: "", NewValue: "backend"},
+		// Changed again since
+		{Organization: "org1", Repository: "repo3", PropertyName: "team", OldValue: "frontend", NewValue: "backend"},
+		// Already reverted
+		{Organization: "org1", Repository: "repo4", PropertyName: "team", OldValue: "frontend", NewValue: "backend"},
+		{Organization: "org1", Repository: "repo5", PropertyName: "team", NewValue: "backend", Skipped: true},
+	})
+
+	config := NewConfig(mockClient)
+	for _, configFile := range rollback.ConfigFiles() {
+		if err := config.AddConfigFile(configFile); err != nil {
+			t.Fatalf("AddConfigFile failed: %v", err)
+		}
+	}
+	ctx := context.Background()
+	if err := config.GenerateRepositories(ctx); err != nil {
+		t.Fatalf("GenerateRepositories failed: %v", err)
+	}
+	diffs, err := config.GenerateDiffs(ctx)
+	if err != nil {
+		t.Fatalf("GenerateDiffs failed: %v", err)
+	}
+	diffs, warnings := rollback.Filter(diffs, config.Renames())
+
+	var changes []string
+	for _, diff := range diffs {
+		changes = append(changes, fmt.Sprintf("%s/%s %s: %q -> %q", diff.Organization, diff.Repository, diff.PropertyName, diff.OldValue, diff.NewValue))
+	}
+	expected := []string{
+		`org1/repo1 team: "backend" -> "frontend"`,
+		`org1/repo2 team: "backend" -> ""`,
+	}
+	if strings.Join(changes, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("unexpected rollback diffs:\n%s", strings.Join(changes, "\n"))
+	}
+	if len(warnings) != 1 || !strings.Contains(warnings[0], "org1/repo3") {
+		t.Errorf("expected a warning for org1/repo3, got %v", warnings)
+	}
+}
+
+func TestPlanRoundTrip(t *testing.T) {
+	diffs := []*PropertyDiff{{Organization: "org1", Repository: "repo1", PropertyName: "team", OldValue: "frontend", NewValue: "backend", Source: "team.yaml"}}
+
+	var buffer bytes.Buffer
+	if err := WritePlan(&buffer, diffs, time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)); err != nil {
+		t.Fatalf("WritePlan failed: %v", err)
+	}
+	plan, err := ReadPlan(&buffer)
+	if err != nil {
+		t.Fatalf("ReadPlan failed: %v", err)
+	}
+	if len(plan.Changes) != 1 || !reflect.DeepEqual(plan.Changes[0], diffs[0]) {
+		t.Errorf("unexpected plan %+v", plan.Changes)
+	}
+
+	if _, err := ReadPlan(strings.NewReader(`{"run_id": "x", "property": "team"}`)); err == nil {
+		t.Error("expected an error for an audit record")
+	}
+}
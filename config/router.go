@@ -0,0 +1,66 @@
+package config
+
+import (
+	"context"
+	"strings"
+
+	"github.com/google/go-github/v74/github"
+)
+
+// Router is a GitHubClient that sends the requests of each organization to the
+// client registered for it, and all other requests to the default client.
+type Router struct {
+	defaultClient GitHubClient
+	clients       map[string]GitHubClient
+}
+
+// NewRouter creates a router with a default client
+func NewRouter(defaultClient GitHubClient) *Router {
+	return &Router{
+		defaultClient: defaultClient,
+		clients:       make(map[string]GitHubClient),
+	}
+}
+
+// Route registers the client for an organization
+func (r *Router) Route(org string, githubClient GitHubClient) {
+	r.clients[strings.ToLower(org)] = githubClient
+}
+
+// ClientFor returns the client for an organization
+func (r *Router) ClientFor(org string) GitHubClient {
+	if githubClient, exists := r.clients[strings.ToLower(org)]; exists {
+		return githubClient
+	}
+	return r.defaultClient
+}
+
+func (r *Router) GetRepository(ctx context.Context, org, repo string) *github.Repository {
+	return r.ClientFor(org).GetRepository(ctx, org, repo)
+}
+
+func (r *Router) GetRepositoryByID(ctx context.Context, org string, id int64) *github.Repository {
+	return r.ClientFor(org).GetRepositoryByID(ctx, org, id)
+}
+
+func (r *Router) UpdateCustomProperties(ctx context.Context, org, repo string, properties map[string]string) error {
+	return r.ClientFor(org).UpdateCustomProperties(ctx, org, repo, properties)
+}
+
+// ListOrganizations lists the organizations visible to the default client
+func (r *Router) ListOrganizations(ctx context.Context) ([]string, error) {
+	return r.defaultClient.ListOrganizations(ctx)
+}
+
+// ListEnterpriseOrganizations lists the organizations of an enterprise with the default client
+func (r *Router) ListEnterpriseOrganizations(ctx context.Context, enterprise string) ([]string, error) {
+	return r.defaultClient.ListEnterpriseOrganizations(ctx, enterprise)
+}
+
+func (r *Router) ListRepositories(ctx context.Context, org string) ([]*github.Repository, error) {
+	return r.ClientFor(org).ListRepositories(ctx, org)
+}
+
+func (r *Router) ListTeamRepositories(ctx context.Context, org, team string) ([]*github.Repository, error) {
+	return r.ClientFor(org).ListTeamRepositories(ctx, org, team)
+}
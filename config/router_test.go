@@ -0,0 +1,83 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestRouter(t *testing.T) {
+	defaultClient := NewMockGitHubClient()
+	defaultClient.AddRepository("org1", "repo1", nil)
+	defaultClient.SetUpdateError(fmt.Errorf("default client"))
+
+	enterpriseClient := NewMockGitHubClient()
+	enterpriseClient.AddRepository("EnterpriseOrg", "repo1", nil)
+	enterpriseClient.SetUpdateError(fmt.Errorf("enterprise client"))
+
+	router := NewRouter(defaultClient)
+	router.Route("EnterpriseOrg", enterpriseClient)
+
+	ctx := context.Background()
+
+	if router.GetRepository(ctx, "org1", "repo1") == nil {
+		t.Error("expected org1/repo1 from the default client")
+	}
+	if router.GetRepository(ctx, "enterpriseorg", "repo1") == nil {
+		t.Error("expected enterpriseorg/repo1 from the enterprise client")
+	}
+	if router.GetRepository(ctx, "org2", "repo1") != nil {
+		t.Error("expected org2/repo1 to be missing")
+	}
+
+	if err := router.UpdateCustomProperties(ctx, "ENTERPRISEORG", "repo1", nil); err == nil || err.Error() != "enterprise client" {
+		t.Errorf("expected update through the enterprise client, got %v", err)
+	}
+	if err := router.UpdateCustomProperties(ctx, "org1", "repo1", nil); err == nil || err.Error() != "default client" {
+		t.Errorf("expected update through the default client, got %v", err)
+	}
+}
+
+func TestConfigWithRouter(t *testing.T) {
+	cloudClient := NewMockGitHubClient()
+	cloudClient.AddRepository("cloud-org", "repo1", map[string]interface{}{"team": "old"})
+
+	enterpriseClient := NewMockGitHubClient()
+	enterpriseClient.AddRepository("enterprise-org", "repo1", map[string]interface{}{"team": "old"})
+	enterpriseClient.SetUpdateError(fmt.Errorf("enterprise API error"))
+
+	router := NewRouter(cloudClient)
+	router.Route("enterprise-org", enterpriseClient)
+
+	config := NewConfig(router)
+	if err := config.LoadConfig(strings.NewReader(`property_name: "team"
+values:
+  - value: "backend"
+    repositories:
+      - name: "cloud-org/repo1"
+      - name: "enterprise-org/repo1"`)); err != nil {
+		t.Fatalf("LoadConfig failed: %v", err)
+	}
+
+	// Each repository is only known to the client of its organization
+	ctx := context.Background()
+	if err := config.GenerateRepositories(ctx); err != nil {
+		t.Fatalf("GenerateRepositories failed: %v", err)
+	}
+
+	diffs, err := config.GenerateDiffs(ctx)
+	if err != nil {
+		t.Fatalf("GenerateDiffs failed: %v", err)
+	}
+	if len(diffs) != 2 {
+		t.Fatalf("expected 2 diffs, got %d", len(diffs))
+	}
+
+	if err := config.ApplyChange(ctx, diffs[0]); err != nil {
+		t.Errorf("expected cloud-org change to succeed, got %v", err)
+	}
+	if err := config.ApplyChange(ctx, diffs[1]); err == nil || !strings.Contains(err.Error(), "enterprise API error") {
+		t.Errorf("expected enterprise-org change to go through the enterprise client, got %v", err)
+	}
+}
@@ -0,0 +1,305 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/google/go-github/v74/github"
+)
+
+const (
+	enterpriseSelectorPrefix = "enterprise:"
+	teamSelectorPrefix       = "team:"
+)
+
+// teamPermissions are the permissions of teams on repositories, from lowest to highest
+var teamPermissions = []string{"pull", "triage", "push", "maintain", "admin"}
+
+// Specificity of repository entries. When a repository is matched by several entries
+// of the same property, the most specific entry wins.
+const (
+	specificityGlobalSelector = iota + 1
+	specificityOrganizationSelector
+	specificityTeamSelector
+	specificityRepositoryName
+)
+
+// repositorySelector matches repositories across organizations. The organization part
+// is an organization name, '*' for every visible organization, or 'enterprise:<slug>'
+// for every organization of an enterprise. The repository part is a glob pattern.
+// Team selectors instead match the repositories a team of the organization has
+// access to, optionally with at least a given permission.
+type repositorySelector struct {
+	organizations string
+	repository    string
+	team          string
+	permission    string
+}
+
+// isRepositorySelector reports whether a repository name is a selector rather than a single repository
+func isRepositorySelector(name string) bool {
+	return strings.HasPrefix(name, enterpriseSelectorPrefix) || strings.HasPrefix(name, teamSelectorPrefix) || strings.ContainsAny(name, "*?[")
+}
+
+func parseRepositorySelector(name string) (*repositorySelector, error) {
+	selector := &repositorySelector{}
+	if team, found := strings.CutPrefix(name, teamSelectorPrefix); found {
+		team, permission, _ := strings.Cut(team, ":")
+		organizationName, teamSlug, err := splitRepositoryName(team)
+		if err != nil {
+			return nil, fmt.Errorf("team %s is not in the format 'org/team-slug'", team)
+		}
+		if permission != "" && !slices.Contains(teamPermissions, permission) {
+			return nil, fmt.Errorf("team %s has unknown permission %s (expected one of %s)", team, permission, strings.Join(teamPermissions, ", "))
+		}
+		selector.organizations = organizationName
+		selector.team = teamSlug
+		selector.permission = permission
+		return selector, nil
+	}
+	if strings.HasPrefix(name, enterpriseSelectorPrefix) {
+		enterprise, repository, found := strings.Cut(strings.TrimPrefix(name, enterpriseSelectorPrefix), "/")
+		if !found {
+			repository = "*"
+		}
+		if enterprise == "" {
+			return nil, fmt.Errorf("repository selector %s has no enterprise", name)
+		}
+		selector.organizations = enterpriseSelectorPrefix + enterprise
+		selector.repository = repository
+	} else {
+		organizationName, repositoryName, err := splitRepositoryName(name)
+		if err != nil {
+			return nil, err
+		}
+		if strings.ContainsAny(organizationName, "?[") || (strings.Contains(organizationName, "*") && organizationName != "*") {
+			return nil, fmt.Errorf("repository selector %s must use an organization name, '*' or 'enterprise:<slug>'", name)
+		}
+		selector.organizations = organizationName
+		selector.repository = repositoryName
+	}
+
+	if selector.repository == "" || strings.Contains(selector.repository, "/") {
+		return nil, fmt.Errorf("repository selector %s is not in the format 'org/pattern'", name)
+	}
+	if _, err := path.Match(selector.repository, ""); err != nil {
+		return nil, fmt.Errorf("repository selector %s has an invalid pattern: %w", name, err)
+	}
+	return selector, nil
+}
+
+// specificity returns how specific the selector is compared to other repository entries
+func (s *repositorySelector) specificity() int {
+	if s.team != "" {
+		return specificityTeamSelector
+	}
+	if s.organizations == "*" || strings.HasPrefix(s.organizations, enterpriseSelectorPrefix) {
+		return specificityGlobalSelector
+	}
+	return specificityOrganizationSelector
+}
+
+func (s *repositorySelector) matches(repository *github.Repository) bool {
+	if s.team != "" {
+		return s.permitted(repository)
+	}
+	matched, _ := path.Match(strings.ToLower(s.repository), strings.ToLower(repository.GetName()))
+	return matched
+}
+
+// permitted reports whether the team has the permission of the selector on a
+// repository listed for the team
+func (s *repositorySelector) permitted(repository *github.Repository) bool {
+	if s.permission == "" {
+		return true
+	}
+	required := slices.Index(teamPermissions, s.permission)
+	for level := len(teamPermissions) - 1; level >= required; level-- {
+		if repository.GetPermissions()[teamPermissions[level]] {
+			return true
+		}
+	}
+	return false
+}
+
+// validateRepositoryEntry checks a repository entry, which has either a name or a team
+func validateRepositoryEntry(repositoryConfig RepositoryConfig) error {
+	switch {
+	case repositoryConfig.Team != "" && repositoryConfig.Name != "":
+		return fmt.Errorf("repository entry %s cannot also select team %s", repositoryConfig.Name, repositoryConfig.Team)
+	case repositoryConfig.Team != "":
+		_, err := parseRepositorySelector(repositoryConfig.entry())
+		return err
+	case repositoryConfig.Permission != "":
+		return fmt.Errorf("repository entry %s has a permission but no team", repositoryConfig.Name)
+	}
+	return validateRepositoryName(repositoryConfig.Name)
+}
+
+// validateRepositoryName checks a repository entry, which is either 'org/repo' or a selector
+func validateRepositoryName(name string) error {
+	if isRepositorySelector(name) {
+		_, err := parseRepositorySelector(name)
+		return err
+	}
+	_, _, err := splitRepositoryName(name)
+	return err
+}
+
+// repositorySpecificity returns the specificity of a repository entry
+func repositorySpecificity(name string) int {
+	if !isRepositorySelector(name) {
+		return specificityRepositoryName
+	}
+	selector, err := parseRepositorySelector(name)
+	if err != nil {
+		return 0
+	}
+	return selector.specificity()
+}
+
+// SetProgress sets a function receiving progress messages while selectors are expanded
+func (c *Config) SetProgress(progress func(message string)) {
+	c.progress = progress
+}
+
+func (c *Config) reportProgress(format string, args ...any) {
+	if c.progress != nil {
+		c.progress(fmt.Sprintf(format, args...))
+	}
+}
+
+// selectorOrganizations returns the organizations a selector applies to
+func (c *Config) selectorOrganizations(ctx context.Context, selector *repositorySelector) ([]string, error) {
+	if organizations, exists := c.organizationLists[selector.organizations]; exists {
+		return organizations, nil
+	}
+
+	var organizations []string
+	var err error
+	switch {
+	case selector.organizations == "*":
+		c.reportProgress("Listing organizations")
+		organizations, err = c.githubClient.ListOrganizations(ctx)
+	case strings.HasPrefix(selector.organizations, enterpriseSelectorPrefix):
+		enterprise := strings.TrimPrefix(selector.organizations, enterpriseSelectorPrefix)
+		c.reportProgress("Listing organizations of enterprise %s", enterprise)
+		organizations, err = c.githubClient.ListEnterpriseOrganizations(ctx, enterprise)
+	default:
+		organizations = []string{selector.organizations}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if c.organizationLists == nil {
+		c.organizationLists = make(map[string][]string)
+	}
+	c.organizationLists[selector.organizations] = organizations
+	return organizations, nil
+}
+
+// organizationRepositories lists the repositories of an organization once per run.
+// Failures are not cached, so that each caller decides how to handle them.
+func (c *Config) organizationRepositories(ctx context.Context, organization string, index, total int) ([]*github.Repository, error) {
+	key := strings.ToLower(organization)
+	if repositories, exists := c.organizationRepositoryLists[key]; exists {
+		return repositories, nil
+	}
+
+	c.reportProgress("Listing repositories of organization %s (%d/%d)", organization, index, total)
+	repositories, err := c.githubClient.ListRepositories(ctx, organization)
+	if err != nil {
+		return nil, err
+	}
+
+	if c.organizationRepositoryLists == nil {
+		c.organizationRepositoryLists = make(map[string][]*github.Repository)
+	}
+	c.organizationRepositoryLists[key] = repositories
+	return repositories, nil
+}
+
+// teamRepositories lists the repositories a team has access to once per run
+func (c *Config) teamRepositories(ctx context.Context, organization, team string) ([]*github.Repository, error) {
+	key := strings.ToLower(organization + "/" + team)
+	if repositories, exists := c.teamRepositoryLists[key]; exists {
+		return repositories, nil
+	}
+
+	c.reportProgress("Listing repositories of team %s/%s", organization, team)
+	repositories, err := c.githubClient.ListTeamRepositories(ctx, organization, team)
+	if err != nil {
+		return nil, err
+	}
+
+	if c.teamRepositoryLists == nil {
+		c.teamRepositoryLists = make(map[string][]*github.Repository)
+	}
+	c.teamRepositoryLists[key] = repositories
+	return repositories, nil
+}
+
+// expandSelector resolves a selector to the repositories it matches. Archived and
+// disabled repositories are never matched by selectors.
+func (c *Config) expandSelector(ctx context.Context, name string) error {
+	key := repositoryKey(name)
+	if _, exists := c.selectorMatches[key]; exists {
+		return nil
+	}
+
+	selector, err := parseRepositorySelector(name)
+	if err != nil {
+		return err
+	}
+
+	organizations, err := c.selectorOrganizations(ctx, selector)
+	if err != nil {
+		return fmt.Errorf("failed to expand repository selector %s: %w", name, err)
+	}
+
+	var matches []*github.Repository
+	for i, organization := range organizations {
+		var repositories []*github.Repository
+		if selector.team != "" {
+			if repositories, err = c.teamRepositories(ctx, organization, selector.team); err != nil {
+				return fmt.Errorf("failed to expand repository selector %s: %w", name, err)
+			}
+		} else {
+			// One inaccessible organization does not prevent changes to all others
+			if repositories, err = c.organizationRepositories(ctx, organization, i+1, len(organizations)); err != nil {
+				c.addWarning("skipping organization %s: %v", organization, err)
+			}
+		}
+		for _, repository := range repositories {
+			if repository.GetArchived() || repository.GetDisabled() || !selector.matches(repository) {
+				continue
+			}
+			c.recordRepository(fullName(repository), repository)
+			matches = append(matches, c.lookupRepository(fullName(repository)))
+		}
+	}
+
+	if c.selectorMatches == nil {
+		c.selectorMatches = make(map[string][]*github.Repository)
+	}
+	c.selectorMatches[key] = matches
+	if len(matches) == 0 {
+		c.addWarning("repository selector %s does not match any repository", name)
+	}
+	return nil
+}
+
+// matchRepositories returns the repositories a repository entry applies to
+func (c *Config) matchRepositories(name string) []*github.Repository {
+	if isRepositorySelector(name) {
+		return c.selectorMatches[repositoryKey(name)]
+	}
+	if repository := c.lookupRepository(name); repository != nil {
+		return []*github.Repository{repository}
+	}
+	return nil
+}
@@ -0,0 +1,256 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/google/go-github/v74/github"
+)
+
+func TestParseRepositorySelector(t *testing.T) {
+	tests := []struct {
+		name          string
+		selector      string
+		organizations string
+		repository    string
+		specificity   int
+		expectError   bool
+	}{
+		{name: "all organizations", selector: "*/*", organizations: "*", repository: "*", specificity: specificityGlobalSelector},
+		{name: "organization glob", selector: "org1/service-*", organizations: "org1", repository: "service-*", specificity: specificityOrganizationSelector},
+		{name: "enterprise", selector: "enterprise:acme", organizations: "enterprise:acme", repository: "*", specificity: specificityGlobalSelector},
+		{name: "enterprise with pattern", selector: "enterprise:acme/api-?", organizations: "enterprise:acme", repository: "api-?", specificity: specificityGlobalSelector},
+		{name: "partial organization glob", selector: "org*/repo", expectError: true},
+		{name: "missing enterprise", selector: "enterprise:/repo", expectError: true},
+		{name: "invalid pattern", selector: "org1/[abc", expectError: true},
+		{name: "missing repository", selector: "enterprise:acme/", expectError: true},
+		{name: "too many parts", selector: "*/repo/*", expectError: true},
+		{name: "team", selector: "team:org1/platform", organizations: "org1", specificity: specificityTeamSelector},
+		{name: "team with permission", selector: "team:org1/platform:maintain", organizations: "org1", specificity: specificityTeamSelector},
+		{name: "team with unknown permission", selector: "team:org1/platform:write", expectError: true},
+		{name: "team without organization", selector: "team:platform", expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if !isRepositorySelector(tt.selector) {
+				t.Fatalf("expected %s to be a selector", tt.selector)
+			}
+			selector, err := parseRepositorySelector(tt.selector)
+			if tt.expectError {
+				if err == nil {
+					t.Errorf("expected error for %s", tt.selector)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if selector.organizations != tt.organizations || selector.repository != tt.repository {
+				t.Errorf("expected %s/%s, got %s/%s", tt.organizations, tt.repository, selector.organizations, selector.repository)
+			}
+			if selector.specificity() != tt.specificity {
+				t.Errorf("expected specificity %d, got %d", tt.specificity, selector.specificity())
+			}
+		})
+	}
+
+	if isRepositorySelector("org1/repo1") {
+		t.Error("expected org1/repo1 not to be a selector")
+	}
+}
+
+func TestGenerateDiffsWithSelectors(t *testing.T) {
+	mockClient := NewMockGitHubClient()
+	mockClient.AddRepository("org1", "service-a", nil)
+	mockClient.AddRepository("org1", "service-b", map[string]interface{}{"tier": "gold"})
+	mockClient.AddRepository("org1", "website", nil)
+	mockClient.AddRepository("org2", "service-c", nil)
+	mockClient.AddRepository("org2", "legacy", nil)
+	mockClient.repositories["org2/legacy"].Archived = github.Ptr(true)
+
+	config := NewConfig(mockClient)
+	var progress []string
+	config.SetProgress(func(message string) {
+		progress = append(progress, message)
+	})
+
+	// The exact name beats the organization glob, which beats the global selector
+	if err := config.LoadConfig(strings.NewReader(`property_name: "tier"
+values:
+  - value: "bronze"
+    repositories:
+      - name: "*/*"
+  - value: "silver"
+    repositories:
+      - name: "org1/service-*"
+  - value: "gold"
+    repositories:
+      - name: "org1/service-b"`)); err != nil {
+		t.Fatalf("LoadConfig failed: %v", err)
+	}
+
+	ctx := context.Background()
+	if err := config.GenerateRepositories(ctx); err != nil {
+		t.Fatalf("GenerateRepositories failed: %v", err)
+	}
+	diffs, err := config.GenerateDiffs(ctx)
+	if err != nil {
+		t.Fatalf("GenerateDiffs failed: %v", err)
+	}
+
+	got := make(map[string]string)
+	for _, diff := range diffs {
+		got[diff.Organization+"/"+diff.Repository] = diff.NewValue
+	}
+	expected := map[string]string{
+		"org1/service-a": "silver",
+		"org1/website":   "bronze",
+		"org2/service-c": "bronze",
+	}
+	if len(got) != len(expected) {
+		t.Fatalf("expected %d diffs, got %v", len(expected), got)
+	}
+	for repository, value := range expected {
+		if got[repository] != value {
+			t.Errorf("expected %s = %s, got %q", repository, value, got[repository])
+		}
+	}
+
+	expectedProgress := []string{
+		"Listing organizations",
+		"Listing repositories of organization org1 (1/2)",
+		"Listing repositories of organization org2 (2/2)",
+	}
+	if strings.Join(progress, "\n") != strings.Join(expectedProgress, "\n") {
+		t.Errorf("unexpected progress: %v", progress)
+	}
+}
+
+func TestGenerateRepositoriesSelectorFailures(t *testing.T) {
+	mockClient := NewMockGitHubClient()
+	mockClient.AddRepository("org1", "repo1", nil)
+	mockClient.AddRepository("org2", "repo1", nil)
+	mockClient.enterpriseOrganizations = map[string][]string{"acme": {"org1", "org2"}}
+	mockClient.listErrors = map[string]error{"org1": fmt.Errorf("forbidden")}
+
+	config := NewConfig(mockClient)
+	if err := config.LoadConfig(strings.NewReader(`property_name: "team"
+values:
+  - value: "backend"
+    repositories:
+      - name: "enterprise:acme"`)); err != nil {
+		t.Fatalf("LoadConfig failed: %v", err)
+	}
+
+	ctx := context.Background()
+	if err := config.GenerateRepositories(ctx); err != nil {
+		t.Fatalf("GenerateRepositories failed: %v", err)
+	}
+	diffs, err := config.GenerateDiffs(ctx)
+	if err != nil {
+		t.Fatalf("GenerateDiffs failed: %v", err)
+	}
+	if len(diffs) != 1 || diffs[0].Organization != "org2" {
+		t.Errorf("expected a single diff for org2, got %v", diffs)
+	}
+	if warnings := config.Warnings(); len(warnings) != 1 || !strings.Contains(warnings[0], "skipping organization org1: forbidden") {
+		t.Errorf("expected a warning for org1, got %v", warnings)
+	}
+
+	config = NewConfig(mockClient)
+	if err := config.LoadConfig(strings.NewReader(`property_name: "team"
+values:
+  - value: "backend"
+    repositories:
+      - name: "enterprise:unknown"`)); err != nil {
+		t.Fatalf("LoadConfig failed: %v", err)
+	}
+	if err := config.GenerateRepositories(ctx); err == nil || !strings.Contains(err.Error(), "enterprise unknown not found") {
+		t.Errorf("expected error for unknown enterprise, got %v", err)
+	}
+}
+
+func TestGenerateDiffsSelectorConflict(t *testing.T) {
+	mockClient := NewMockGitHubClient()
+	mockClient.AddRepository("org1", "service-a", nil)
+
+	config := NewConfig(mockClient)
+	if err := config.LoadConfig(strings.NewReader(`property_name: "tier"
+values:
+  - value: "silver"
+    repositories:
+      - name: "org1/service-*"
+  - value: "gold"
+    repositories:
+      - name: "org1/*-a"`)); err != nil {
+		t.Fatalf("LoadConfig failed: %v", err)
+	}
+
+	ctx := context.Background()
+	if err := config.GenerateRepositories(ctx); err != nil {
+		t.Fatalf("GenerateRepositories failed: %v", err)
+	}
+	if _, err := config.GenerateDiffs(ctx); err == nil || !strings.Contains(err.Error(), "conflicting values") {
+		t.Errorf("expected conflict error, got %v", err)
+	}
+}
+
+func TestGenerateDiffsTeamSelector(t *testing.T) {
+	mockClient := NewMockGitHubClient()
+	mockClient.AddRepository("org1", "api", nil)
+	mockClient.AddRepository("org1", "web", nil)
+	mockClient.AddRepository("org1", "docs", nil)
+	mockClient.AddTeamRepository("org1", "platform", "api", "admin")
+	mockClient.AddTeamRepository("org1", "platform", "web", "pull")
+
+	config := NewConfig(mockClient)
+	if err := config.LoadConfig(strings.NewReader(`property_name: "team"
+values:
+  - value: "unowned"
+    repositories:
+      - name: "org1/*"
+  - value: "platform"
+    repositories:
+      - team: "org1/platform"
+        permission: "push"
+`)); err != nil {
+		t.Fatalf("LoadConfig failed: %v", err)
+	}
+	ctx := context.Background()
+	if err := config.GenerateRepositories(ctx); err != nil {
+		t.Fatalf("GenerateRepositories failed: %v", err)
+	}
+	diffs, err := config.GenerateDiffs(ctx)
+	if err != nil {
+		t.Fatalf("GenerateDiffs failed: %v", err)
+	}
+
+	values := make(map[string]string)
+	for _, diff := range diffs {
+		values[diff.Repository] = diff.NewValue
+	}
+	// The team selector beats the organization selector, but only where the team can push
+	expected := map[string]string{"api": "platform", "web": "unowned", "docs": "unowned"}
+	if fmt.Sprint(values) != fmt.Sprint(expected) {
+		t.Errorf("expected %v, got %v", expected, values)
+	}
+}
+
+func TestValidateRepositoryEntry(t *testing.T) {
+	for _, entry := range []RepositoryConfig{
+		{Name: "org1/repo1", Team: "org1/platform"},
+		{Name: "org1/repo1", Permission: "push"},
+		{Team: "org1/platform", Permission: "write"},
+		{},
+	} {
+		if err := validateRepositoryEntry(entry); err == nil {
+			t.Errorf("expected an error for %+v", entry)
+		}
+	}
+	if err := validateRepositoryEntry(RepositoryConfig{Team: "org1/platform", Permission: "triage"}); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
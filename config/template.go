@@ -0,0 +1,117 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/goccy/go-yaml/ast"
+	"github.com/goccy/go-yaml/parser"
+	"github.com/google/go-github/v74/github"
+)
+
+// templateFuncs are the functions available in value templates in addition to the
+// builtin ones of text/template
+var templateFuncs = template.FuncMap{
+	"lower":      strings.ToLower,
+	"upper":      strings.ToUpper,
+	"trimPrefix": strings.TrimPrefix,
+	"trimSuffix": strings.TrimSuffix,
+}
+
+// isTemplate reports whether a configured value is a template rendered per repository
+func isTemplate(value string) bool {
+	return strings.Contains(value, "{{")
+}
+
+// parseValueTemplate parses a value template. Referencing a field that does not exist
+// is an error when the template is rendered.
+func parseValueTemplate(value string) (*template.Template, error) {
+	return template.New("value").Funcs(templateFuncs).Option("missingkey=error").Parse(value)
+}
+
+// renderValue renders a value template against the fields of a repository, e.g.
+// '{{ .Owner.Login }}-{{ .Language }}'. Unset fields render as empty strings.
+func renderValue(valueTemplate *template.Template, repository *github.Repository) (string, error) {
+	var rendered strings.Builder
+	if err := valueTemplate.Execute(&rendered, templateData(reflect.ValueOf(repository))); err != nil {
+		return "", err
+	}
+	return rendered.String(), nil
+}
+
+// templateData converts a value into maps keyed by field name, so that nil pointers,
+// which go-github uses for fields missing from API responses, render as empty
+// strings instead of "<nil>"
+func templateData(value reflect.Value) any {
+	switch value.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		if value.IsNil() {
+			return ""
+		}
+		return templateData(value.Elem())
+	case reflect.Struct:
+		if value.Type() == reflect.TypeOf(time.Time{}) || value.Type() == reflect.TypeOf(github.Timestamp{}) {
+			return value.Interface()
+		}
+		fields := make(map[string]any, value.NumField())
+		for i := 0; i < value.NumField(); i++ {
+			if field := value.Type().Field(i); field.IsExported() {
+				fields[field.Name] = templateData(value.Field(i))
+			}
+		}
+		return fields
+	case reflect.Slice, reflect.Array:
+		items := make([]any, value.Len())
+		for i := range items {
+			items[i] = templateData(value.Index(i))
+		}
+		return items
+	case reflect.Map:
+		entries := make(map[string]any, value.Len())
+		iterator := value.MapRange()
+		for iterator.Next() {
+			entries[fmt.Sprint(iterator.Key().Interface())] = templateData(iterator.Value())
+		}
+		return entries
+	default:
+		return value.Interface()
+	}
+}
+
+// valueLocation describes where a value is configured, for error messages
+func valueLocation(configFile *ConfigFile, index int) string {
+	location := fmt.Sprintf("value #%d of property '%s'", index+1, configFile.PropertyName)
+	if configFile.Source == "" {
+		return location
+	}
+	if line := configFile.Values[index].Line; line > 0 {
+		return fmt.Sprintf("%s (%s:%d)", location, configFile.Source, line)
+	}
+	return fmt.Sprintf("%s (%s)", location, configFile.Source)
+}
+
+// setValueLines records the line of each value in the YAML source of a configuration
+// file. Like decoding, it only considers the first document.
+func setValueLines(data []byte, configFile *ConfigFile) {
+	file, err := parser.ParseBytes(data, 0)
+	if err != nil || len(file.Docs) == 0 {
+		return
+	}
+	values, ok := mappingField(file.Docs[0].Body, "values").(*ast.SequenceNode)
+	if !ok {
+		return
+	}
+	for i, value := range values.Values {
+		if i >= len(configFile.Values) {
+			break
+		}
+		node := mappingField(value, "value")
+		if node == nil {
+			node = value
+		}
+		configFile.Values[i].Line = node.GetToken().Position.Line
+	}
+}
@@ -0,0 +1,131 @@
+package config
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-github/v74/github"
+)
+
+func TestGenerateDiffsTemplatedValues(t *testing.T) {
+	mockClient := NewMockGitHubClient()
+	mockClient.AddRepository("org1", "repo1", nil)
+	mockClient.repositories["org1/repo1"].Language = github.Ptr("Go")
+	mockClient.AddRepository("org1", "repo2", map[string]interface{}{"primary-language": "org1-"})
+
+	config := NewConfig(mockClient)
+	if err := config.LoadConfig(strings.NewReader(`property_name: "primary-language"
+values:
+  - value: "{{ .Owner.Login }}-{{ .Language | lower }}"
+    repositories:
+      - name: "org1/*"
+`)); err != nil {
+		t.Fatalf("LoadConfig failed: %v", err)
+	}
+	ctx := context.Background()
+	if err := config.GenerateRepositories(ctx); err != nil {
+		t.Fatalf("GenerateRepositories failed: %v", err)
+	}
+	diffs, err := config.GenerateDiffs(ctx)
+	if err != nil {
+		t.Fatalf("GenerateDiffs failed: %v", err)
+	}
+	// repo2 has no language, which renders as an empty string and matches its current value
+	if len(diffs) != 1 || diffs[0].Repository != "repo1" || diffs[0].NewValue != "org1-go" {
+		t.Errorf("expected org1/repo1 to be set to org1-go, got %+v", diffs)
+	}
+}
+
+func TestTemplatedValueErrors(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tier.yaml")
+	if err := os.WriteFile(path, []byte(`property_name: "tier"
+values:
+  - value: "static"
+    repositories:
+      - name: "org1/repo1"
+  - value: "{{ .Unknown }}"
+    repositories:
+      - name: "org1/repo2"
+`), 0o600); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+
+	mockClient := NewMockGitHubClient()
+	mockClient.AddRepository("org1", "repo1", nil)
+	mockClient.AddRepository("org1", "repo2", nil)
+	config := NewConfig(mockClient)
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("failed to open config: %v", err)
+	}
+	err = config.LoadConfig(file)
+	file.Close()
+	if err != nil {
+		t.Fatalf("LoadConfig failed: %v", err)
+	}
+	ctx := context.Background()
+	if err := config.GenerateRepositories(ctx); err != nil {
+		t.Fatalf("GenerateRepositories failed: %v", err)
+	}
+	_, err = config.GenerateDiffs(ctx)
+	if err == nil || !strings.Contains(err.Error(), path+":6") || !strings.Contains(err.Error(), "org1/repo2") {
+		t.Errorf("expected a render error located at %s:6, got %v", path, err)
+	}
+
+	err = NewConfig(mockClient).LoadConfig(strings.NewReader(`property_name: "tier"
+values:
+  - value: "{{ .Owner.Login "
+    repositories:
+      - name: "org1/repo1"
+`))
+	if err == nil || !strings.Contains(err.Error(), "value #1 of property 'tier' is not a valid template") {
+		t.Errorf("expected a template syntax error, got %v", err)
+	}
+}
+
+func TestLiteralValues(t *testing.T) {
+	mockClient := NewMockGitHubClient()
+	mockClient.AddRepository("org1", "repo1", nil)
+	config := NewConfig(mockClient)
+	if err := config.AddConfigFile(&ConfigFile{
+		PropertyName: "note",
+		Literal:      true,
+		Values:       []ValueConfig{{Value: "{{ not a template", Repositories: []RepositoryConfig{{Name: "org1/repo1"}}}},
+	}); err != nil {
+		t.Fatalf("AddConfigFile failed: %v", err)
+	}
+	ctx := context.Background()
+	if err := config.GenerateRepositories(ctx); err != nil {
+		t.Fatalf("GenerateRepositories failed: %v", err)
+	}
+	diffs, err := config.GenerateDiffs(ctx)
+	if err != nil {
+		t.Fatalf("GenerateDiffs failed: %v", err)
+	}
+	if len(diffs) != 1 || diffs[0].NewValue != "{{ not a template" {
+		t.Errorf("expected the literal value, got %+v", diffs)
+	}
+}
+
+func TestSetValueLinesFirstDocument(t *testing.T) {
+	data := []byte(`property_name: "team"
+values:
+  - value: "backend"
+    repositories:
+      - name: "org1/repo1"
+---
+property_name: "tier"
+values:
+  - value: "1"
+    repositories:
+      - name: "org1/repo1"
+`)
+	configFile := &ConfigFile{Values: []ValueConfig{{Value: "backend"}}}
+	setValueLines(data, configFile)
+	if configFile.Values[0].Line != 3 {
+		t.Errorf("expected the value on line 3, got %d", configFile.Values[0].Line)
+	}
+}
@@ -0,0 +1,106 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+
+	"github.com/goccy/go-yaml"
+)
+
+// variableReference matches ${NAME} references, and $$ escaping a literal $
+var variableReference = regexp.MustCompile(`\$\$|\$\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+
+// SetVariables sets the variables interpolated into configuration files loaded
+// afterwards. They take precedence over environment variables of the same name.
+func (c *Config) SetVariables(variables map[string]string) {
+	c.variables = variables
+}
+
+// lookupVariable returns the value of a variable, falling back to the environment
+func (c *Config) lookupVariable(name string) (string, bool) {
+	if value, exists := c.variables[name]; exists {
+		return value, true
+	}
+	return os.LookupEnv(name)
+}
+
+// interpolate replaces variable references in a string
+func (c *Config) interpolate(s string) (string, error) {
+	var undefined []string
+	result := variableReference.ReplaceAllStringFunc(s, func(reference string) string {
+		if reference == "$$" {
+			return "$"
+		}
+		name := reference[2 : len(reference)-1]
+		value, exists := c.lookupVariable(name)
+		if !exists {
+			undefined = append(undefined, name)
+		}
+		return value
+	})
+	if len(undefined) > 0 {
+		return "", fmt.Errorf("undefined variable %s", undefined[0])
+	}
+	return result, nil
+}
+
+// interpolateConfigFile replaces variable references in every string field of a
+// configuration file, e.g. '${ORG}/service' in a repository name or the path of an
+// external file. Rows read from external files are not interpolated.
+func (c *Config) interpolateConfigFile(configFile *ConfigFile) error {
+	var errs []error
+	interpolateField := func(field *string, location string) {
+		interpolated, err := c.interpolate(*field)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", location, err))
+		}
+		*field = interpolated
+	}
+
+	interpolateField(&configFile.PropertyName, "property_name")
+	for i := range configFile.Values {
+		value := &configFile.Values[i]
+		interpolateField(&value.Value, valueLocation(configFile, i))
+
+		for j := range value.Repositories {
+			repositoryConfig := &value.Repositories[j]
+			for _, field := range []*string{&repositoryConfig.Name, &repositoryConfig.Team, &repositoryConfig.Permission} {
+				interpolateField(field, fmt.Sprintf("repository %s of %s", *field, valueLocation(configFile, i)))
+			}
+		}
+		if repositoriesFrom := value.RepositoriesFrom; repositoriesFrom != nil {
+			for _, field := range []*string{&repositoriesFrom.File, &repositoriesFrom.Column, &repositoriesFrom.OrganizationColumn} {
+				interpolateField(field, fmt.Sprintf("repositories_from of %s", valueLocation(configFile, i)))
+			}
+		}
+	}
+	if valuesFrom := configFile.ValuesFrom; valuesFrom != nil {
+		for _, field := range []*string{&valuesFrom.File, &valuesFrom.RepositoryColumn, &valuesFrom.ValueColumn, &valuesFrom.OrganizationColumn} {
+			interpolateField(field, fmt.Sprintf("values_from of property '%s'", configFile.PropertyName))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// ReadVariables reads variables from a YAML mapping of names to scalar values
+func ReadVariables(r io.Reader) (map[string]string, error) {
+	var raw map[string]any
+	if err := yaml.NewDecoder(r).Decode(&raw); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("failed to parse variables: %w", err)
+	}
+	variables := make(map[string]string, len(raw))
+	for name, value := range raw {
+		switch value.(type) {
+		case map[string]any, []any:
+			return nil, fmt.Errorf("variable %s must be a scalar value", name)
+		case nil:
+			variables[name] = ""
+		default:
+			variables[name] = fmt.Sprint(value)
+		}
+	}
+	return variables, nil
+}
@@ -0,0 +1,105 @@
+package config
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoadConfigVariables(t *testing.T) {
+	t.Setenv("ORG", "staging-org")
+	t.Setenv("TEAM", "from-environment")
+
+	config := NewConfig(NewMockGitHubClient())
+	config.SetVariables(map[string]string{"TEAM": "platform"})
+	if err := config.LoadConfig(strings.NewReader(`property_name: "team"
+values:
+  - value: "${TEAM}"
+    repositories:
+      - name: "${ORG}/service"
+      - team: "${ORG}/platform"
+  - value: "costs $$5"
+    repositories:
+      - name: "${ORG}/billing"
+`)); err != nil {
+		t.Fatalf("LoadConfig failed: %v", err)
+	}
+
+	values := config.configurationFiles[0].Values
+	if values[0].Value != "platform" || values[0].Repositories[0].Name != "staging-org/service" || values[0].Repositories[1].Team != "staging-org/platform" {
+		t.Errorf("unexpected interpolation: %+v", values[0])
+	}
+	if values[1].Value != "costs $5" || values[1].Repositories[0].Name != "staging-org/billing" {
+		t.Errorf("unexpected interpolation: %+v", values[1])
+	}
+}
+
+func TestLoadConfigVariablesInFields(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"team.yaml": `property_name: "${PROPERTY}"
+values:
+  - value: "backend"
+    repositories:
+      - team: "org1/platform"
+        permission: "${PERMISSION}"
+    repositories_from:
+      file: "${ENVIRONMENT}.csv"
+      column: "${COLUMN}"
+values_from:
+  file: "${ENVIRONMENT}-values.csv"
+  value_column: "${COLUMN}"
+`,
+		"staging.csv":        "service\norg1/repo1\n",
+		"staging-values.csv": "repository,service\norg1/repo2,frontend\n",
+	})
+
+	config := NewConfig(NewMockGitHubClient())
+	config.SetVariables(map[string]string{"PROPERTY": "team", "PERMISSION": "admin", "ENVIRONMENT": "staging", "COLUMN": "service"})
+	if err := loadConfigFile(config, filepath.Join(dir, "team.yaml")); err != nil {
+		t.Fatalf("LoadConfig failed: %v", err)
+	}
+
+	configFile := config.configurationFiles[0]
+	if configFile.PropertyName != "team" || configFile.Values[0].Repositories[0].Permission != "admin" {
+		t.Errorf("unexpected interpolation: %+v", configFile)
+	}
+	var entries []string
+	for _, value := range configFile.Values {
+		for _, repositoryConfig := range value.Repositories {
+			entries = append(entries, value.Value+" "+repositoryConfig.entry())
+		}
+	}
+	expected := []string{"backend team:org1/platform:admin", "backend org1/repo1", "frontend org1/repo2"}
+	if strings.Join(entries, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("unexpected entries:\n%s", strings.Join(entries, "\n"))
+	}
+}
+
+func TestLoadConfigUndefinedVariable(t *testing.T) {
+	err := NewConfig(NewMockGitHubClient()).LoadConfig(strings.NewReader(`property_name: "team"
+values:
+  - value: "backend"
+    repositories:
+      - name: "${UNDEFINED_ORGANIZATION_VARIABLE}/service"
+`))
+	if err == nil || !strings.Contains(err.Error(), "undefined variable UNDEFINED_ORGANIZATION_VARIABLE") {
+		t.Errorf("expected an undefined variable error, got %v", err)
+	}
+}
+
+func TestReadVariables(t *testing.T) {
+	variables, err := ReadVariables(strings.NewReader("ORG: production-org\nREPLICAS: 3\nEMPTY:\n"))
+	if err != nil {
+		t.Fatalf("ReadVariables failed: %v", err)
+	}
+	if variables["ORG"] != "production-org" || variables["REPLICAS"] != "3" || variables["EMPTY"] != "" {
+		t.Errorf("unexpected variables %v", variables)
+	}
+
+	if _, err := ReadVariables(strings.NewReader("ORG:\n  name: nested\n")); err == nil {
+		t.Error("expected an error for a nested value")
+	}
+	if variables, err := ReadVariables(strings.NewReader("")); err != nil || len(variables) != 0 {
+		t.Errorf("expected no variables for an empty file, got %v, %v", variables, err)
+	}
+}
@@ -0,0 +1,265 @@
+package credential
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/goccy/go-yaml"
+)
+
+// DefaultHost is the hostname of github.com
+const DefaultHost = "github.com"
+
+// Provider supplies a token for a GitHub host
+type Provider interface {
+	// Name describes the provider for diagnostics
+	Name() string
+	// Token returns the token for the host, or an empty string if the provider has none
+	Token(ctx context.Context, host string) (string, error)
+}
+
+// Credential is a token together with the source it was read from
+type Credential struct {
+	Token  string
+	Source string
+}
+
+// Redacted returns the source and a redacted form of the token for diagnostics
+func (c *Credential) Redacted() string {
+	return fmt.Sprintf("%s (%s)", c.Source, Redact(c.Token))
+}
+
+// tokenPrefixes identify the kind of GitHub token and are safe to show
+var tokenPrefixes = []string{"github_pat_", "ghp_", "gho_", "ghu_", "ghs_", "ghr_"}
+
+// Redact hides all but the prefix and the last four characters of a token
+func Redact(token string) string {
+	if len(token) < 12 {
+		return "****"
+	}
+	prefix := ""
+	for _, tokenPrefix := range tokenPrefixes {
+		if strings.HasPrefix(token, tokenPrefix) {
+			prefix = tokenPrefix
+			break
+		}
+	}
+	return prefix + "****" + token[len(token)-4:]
+}
+
+// Chain tries providers in order and uses the first token found
+type Chain struct {
+	Providers []Provider
+}
+
+// NewChain creates a credential chain
+func NewChain(providers ...Provider) *Chain {
+	return &Chain{Providers: providers}
+}
+
+// Resolve returns the first credential available for the host
+func (c *Chain) Resolve(ctx context.Context, host string) (*Credential, error) {
+	var errs []error
+	sources := make([]string, 0, len(c.Providers))
+	for _, provider := range c.Providers {
+		sources = append(sources, provider.Name())
+
+		token, err := provider.Token(ctx, host)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", provider.Name(), err))
+			continue
+		}
+		if token != "" {
+			return &Credential{Token: token, Source: provider.Name()}, nil
+		}
+	}
+
+	err := fmt.Errorf("no credentials found for %s (tried %s)", host, strings.Join(sources, ", "))
+	if len(errs) > 0 {
+		return nil, errors.Join(append([]error{err}, errs...)...)
+	}
+	return nil, err
+}
+
+// EnvProvider reads the token from environment variables. Like the gh CLI, it uses
+// GH_TOKEN and GITHUB_TOKEN for github.com and GH_ENTERPRISE_TOKEN and
+// GITHUB_ENTERPRISE_TOKEN for other hosts.
+type EnvProvider struct {
+	lookupEnv func(string) (string, bool)
+	source    string
+}
+
+// NewEnvProvider creates a provider reading environment variables
+func NewEnvProvider() *EnvProvider {
+	return &EnvProvider{lookupEnv: os.LookupEnv}
+}
+
+func (p *EnvProvider) Name() string {
+	if p.source != "" {
+		return "environment variable " + p.source
+	}
+	return "environment variables"
+}
+
+func (p *EnvProvider) Token(ctx context.Context, host string) (string, error) {
+	variables := []string{"GH_TOKEN", "GITHUB_TOKEN"}
+	if !isDefaultHost(host) {
+		variables = []string{"GH_ENTERPRISE_TOKEN", "GITHUB_ENTERPRISE_TOKEN"}
+	}
+	for _, variable := range variables {
+		if token, ok := p.lookupEnv(variable); ok && token != "" {
+			p.source = variable
+			return token, nil
+		}
+	}
+	return "", nil
+}
+
+// VariableProvider reads the token from a single named environment variable
+type VariableProvider struct {
+	Variable string
+}
+
+func (p *VariableProvider) Name() string {
+	return "environment variable " + p.Variable
+}
+
+func (p *VariableProvider) Token(ctx context.Context, host string) (string, error) {
+	return os.Getenv(p.Variable), nil
+}
+
+// FileProvider reads the token from a file
+type FileProvider struct {
+	Path string
+}
+
+func (p *FileProvider) Name() string {
+	return "token file " + p.Path
+}
+
+func (p *FileProvider) Token(ctx context.Context, host string) (string, error) {
+	data, err := os.ReadFile(p.Path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read token file: %w", err)
+	}
+	return strings.TrimSpace(string(data)), nil
+}
+
+// CommandProvider runs an external command and reads the token from its output.
+// The host is passed to the command in the GITHUB_HOST environment variable.
+type CommandProvider struct {
+	Command string
+}
+
+func (p *CommandProvider) Name() string {
+	return "credential command"
+}
+
+func (p *CommandProvider) Token(ctx context.Context, host string) (string, error) {
+	command := exec.CommandContext(ctx, "sh", "-c", p.Command)
+	command.Env = append(os.Environ(), "GITHUB_HOST="+host)
+	command.Stderr = os.Stderr
+	output, err := command.Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to run credential command: %w", err)
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+// GhCLIProvider reads the token of the gh CLI. It uses the token stored in the gh
+// hosts.yml file, and falls back to `gh auth token` when gh keeps the token in the
+// system keyring.
+type GhCLIProvider struct {
+	// ConfigDir overrides the gh configuration directory
+	ConfigDir string
+	// lookPath finds the gh executable, it is replaced in tests
+	lookPath func(string) (string, error)
+	source   string
+}
+
+// NewGhCLIProvider creates a provider reading gh CLI credentials
+func NewGhCLIProvider() *GhCLIProvider {
+	return &GhCLIProvider{lookPath: exec.LookPath}
+}
+
+func (p *GhCLIProvider) Name() string {
+	if p.source != "" {
+		return p.source
+	}
+	return "gh CLI"
+}
+
+func (p *GhCLIProvider) Token(ctx context.Context, host string) (string, error) {
+	hostsFile := filepath.Join(p.configDir(), "hosts.yml")
+	token, err := readHostsFileToken(hostsFile, host)
+	if err != nil {
+		return "", err
+	}
+	if token != "" {
+		p.source = "gh CLI " + hostsFile
+		return token, nil
+	}
+
+	ghPath, err := p.lookPath("gh")
+	if err != nil {
+		return "", nil
+	}
+	output, err := exec.CommandContext(ctx, ghPath, "auth", "token", "--hostname", host).Output()
+	if err != nil {
+		// gh is installed but not logged in to the host
+		return "", nil
+	}
+	p.source = "gh auth token"
+	return strings.TrimSpace(string(output)), nil
+}
+
+func (p *GhCLIProvider) configDir() string {
+	if p.ConfigDir != "" {
+		return p.ConfigDir
+	}
+	if dir := os.Getenv("GH_CONFIG_DIR"); dir != "" {
+		return dir
+	}
+	if dir := os.Getenv("XDG_CONFIG_HOME"); dir != "" {
+		return filepath.Join(dir, "gh")
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".config", "gh")
+}
+
+type ghHost struct {
+	OAuthToken string `yaml:"oauth_token"`
+}
+
+func readHostsFileToken(path, host string) (string, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	var hosts map[string]ghHost
+	if err := yaml.Unmarshal(data, &hosts); err != nil {
+		return "", fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	for name, entry := range hosts {
+		if strings.EqualFold(name, host) {
+			return entry.OAuthToken, nil
+		}
+	}
+	return "", nil
+}
+
+func isDefaultHost(host string) bool {
+	return host == "" || strings.EqualFold(host, DefaultHost)
+}
@@ -0,0 +1,203 @@
+package credential
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// staticProvider is a Provider returning a fixed token or error
+type staticProvider struct {
+	name  string
+	token string
+	err   error
+}
+
+func (p *staticProvider) Name() string {
+	return p.name
+}
+
+func (p *staticProvider) Token(ctx context.Context, host string) (string, error) {
+	return p.token, p.err
+}
+
+func TestRedact(t *testing.T) {
+	testCases := []struct {
+		token    string
+		expected string
+	}{
+		{"ghp_1234567890abcdefghij", "ghp_****ghij"},
+		{"github_pat_11ABCDEFG0123456789", "github_pat_****6789"},
+		{"0123456789abcdef", "****cdef"},
+		{"short", "****"},
+		{"", "****"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.token, func(t *testing.T) {
+			if result := Redact(tc.token); result != tc.expected {
+				t.Errorf("expected %q, got %q", tc.expected, result)
+			}
+		})
+	}
+}
+
+func TestChainResolve(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("first token wins", func(t *testing.T) {
+		chain := NewChain(
+			&staticProvider{name: "empty"},
+			&staticProvider{name: "first", token: "token-1"},
+			&staticProvider{name: "second", token: "token-2"},
+		)
+		credential, err := chain.Resolve(ctx, DefaultHost)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if credential.Token != "token-1" || credential.Source != "first" {
+			t.Errorf("unexpected credential: %+v", credential)
+		}
+	})
+
+	t.Run("provider errors are skipped", func(t *testing.T) {
+		chain := NewChain(
+			&staticProvider{name: "broken", err: errors.New("boom")},
+			&staticProvider{name: "working", token: "token"},
+		)
+		credential, err := chain.Resolve(ctx, DefaultHost)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if credential.Source != "working" {
+			t.Errorf("expected source 'working', got %q", credential.Source)
+		}
+	})
+
+	t.Run("no credentials", func(t *testing.T) {
+		chain := NewChain(
+			&staticProvider{name: "empty"},
+			&staticProvider{name: "broken", err: errors.New("boom")},
+		)
+		_, err := chain.Resolve(ctx, "ghe.example.com")
+		if err == nil {
+			t.Fatal("expected error but got none")
+		}
+		for _, expected := range []string{"no credentials found for ghe.example.com", "tried empty, broken", "broken: boom"} {
+			if !strings.Contains(err.Error(), expected) {
+				t.Errorf("expected error to contain %q, got %q", expected, err.Error())
+			}
+		}
+	})
+}
+
+func TestEnvProvider(t *testing.T) {
+	env := map[string]string{
+		"GITHUB_TOKEN":        "github-token",
+		"GH_ENTERPRISE_TOKEN": "enterprise-token",
+	}
+	provider := &EnvProvider{lookupEnv: func(key string) (string, bool) {
+		value, ok := env[key]
+		return value, ok
+	}}
+	ctx := context.Background()
+
+	token, err := provider.Token(ctx, DefaultHost)
+	if err != nil || token != "github-token" {
+		t.Errorf("expected github-token, got %q (%v)", token, err)
+	}
+	if provider.Name() != "environment variable GITHUB_TOKEN" {
+		t.Errorf("unexpected name %q", provider.Name())
+	}
+
+	env["GH_TOKEN"] = "gh-token"
+	if token, _ := provider.Token(ctx, DefaultHost); token != "gh-token" {
+		t.Errorf("expected GH_TOKEN to take precedence, got %q", token)
+	}
+
+	if token, _ := provider.Token(ctx, "ghe.example.com"); token != "enterprise-token" {
+		t.Errorf("expected enterprise-token, got %q", token)
+	}
+}
+
+func TestVariableProvider(t *testing.T) {
+	t.Setenv("TEAM_A_TOKEN", "team-a-token")
+
+	provider := &VariableProvider{Variable: "TEAM_A_TOKEN"}
+	token, err := provider.Token(context.Background(), DefaultHost)
+	if err != nil || token != "team-a-token" {
+		t.Errorf("expected team-a-token, got %q (%v)", token, err)
+	}
+	if provider.Name() != "environment variable TEAM_A_TOKEN" {
+		t.Errorf("unexpected name %q", provider.Name())
+	}
+}
+
+func TestFileProvider(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "token")
+	if err := os.WriteFile(path, []byte("file-token\n"), 0o600); err != nil {
+		t.Fatalf("failed to write token file: %v", err)
+	}
+
+	token, err := (&FileProvider{Path: path}).Token(context.Background(), DefaultHost)
+	if err != nil || token != "file-token" {
+		t.Errorf("expected file-token, got %q (%v)", token, err)
+	}
+
+	if _, err := (&FileProvider{Path: path + ".missing"}).Token(context.Background(), DefaultHost); err == nil {
+		t.Error("expected error for missing file")
+	}
+}
+
+func TestCommandProvider(t *testing.T) {
+	provider := &CommandProvider{Command: `echo "token-for-$GITHUB_HOST"`}
+
+	token, err := provider.Token(context.Background(), "ghe.example.com")
+	if err != nil || token != "token-for-ghe.example.com" {
+		t.Errorf("expected token-for-ghe.example.com, got %q (%v)", token, err)
+	}
+
+	if _, err := (&CommandProvider{Command: "exit 1"}).Token(context.Background(), DefaultHost); err == nil {
+		t.Error("expected error for failing command")
+	}
+}
+
+func TestGhCLIProvider(t *testing.T) {
+	configDir := t.TempDir()
+	hostsFile := `github.com:
+    user: octocat
+    oauth_token: gho_hostsfiletoken1234
+    git_protocol: https
+ghe.example.com:
+    user: octocat
+    git_protocol: https
+`
+	if err := os.WriteFile(filepath.Join(configDir, "hosts.yml"), []byte(hostsFile), 0o600); err != nil {
+		t.Fatalf("failed to write hosts.yml: %v", err)
+	}
+
+	provider := &GhCLIProvider{
+		ConfigDir: configDir,
+		lookPath: func(string) (string, error) {
+			return "", errors.New("not found")
+		},
+	}
+	ctx := context.Background()
+
+	token, err := provider.Token(ctx, DefaultHost)
+	if err != nil || token != "gho_hostsfiletoken1234" {
+		t.Errorf("expected token from hosts.yml, got %q (%v)", token, err)
+	}
+	if !strings.Contains(provider.Name(), "hosts.yml") {
+		t.Errorf("expected name to mention hosts.yml, got %q", provider.Name())
+	}
+
+	// The token is in the keyring and gh is not installed
+	token, err = provider.Token(ctx, "ghe.example.com")
+	if err != nil || token != "" {
+		t.Errorf("expected no token, got %q (%v)", token, err)
+	}
+}
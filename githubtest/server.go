@@ -0,0 +1,718 @@
+// Package githubtest provides an in-memory fake of the GitHub REST API for tests.
+//
+// The server implements the repository, organization, team and custom property
+// endpoints used by this tool, including pagination, rate limits, conditional
+// requests and injected failures, so that clients can be tested over real HTTP.
+package githubtest
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/go-github/v74/github"
+)
+
+// Repository is a repository served by the fake server
+type Repository struct {
+	ID           int64
+	Organization string
+	Name         string
+	Archived     bool
+	Disabled     bool
+	IsTemplate   bool
+	// Properties are the custom property values: strings, or string slices for multi_select
+	Properties map[string]any
+}
+
+// FullName returns the 'org/repo' name of the repository
+func (r *Repository) FullName() string {
+	return r.Organization + "/" + r.Name
+}
+
+// Server is a fake GitHub API server. Requests are served under both / and the
+// GitHub Enterprise Server prefix /api/v3/, so a client can point at URL() with
+// either go-github's BaseURL or the CLI's --base-url flag.
+type Server struct {
+	server *httptest.Server
+
+	mu           sync.Mutex
+	nextID       int64
+	repositories []*Repository
+	// redirects maps the old lowercase names of renamed repositories to their ID
+	redirects   map[string]int64
+	definitions map[string][]*github.CustomProperty
+	enterprises map[string][]string
+	// teams maps lowercase 'org/team-slug' to the permission of the team on each repository ID
+	teams    map[string]map[int64]string
+	failures []*failure
+	requests []string
+	pageSize int
+	token    string
+	user     string
+
+	rateLimit     int
+	rateRemaining int
+	rateReset     time.Time
+}
+
+type failure struct {
+	method string
+	path   string
+	status int
+	times  int
+}
+
+// NewServer starts a fake server. It is closed when the test finishes.
+func NewServer(t interface{ Cleanup(func()) }) *Server {
+	s := &Server{
+		redirects:     make(map[string]int64),
+		definitions:   make(map[string][]*github.CustomProperty),
+		enterprises:   make(map[string][]string),
+		teams:         make(map[string]map[int64]string),
+		user:          "octocat",
+		rateLimit:     5000,
+		rateRemaining: 5000,
+		rateReset:     time.Now().Add(time.Hour),
+	}
+	s.server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	t.Cleanup(s.server.Close)
+	return s
+}
+
+// URL returns the REST API base URL of the server, ending with a slash
+func (s *Server) URL() string {
+	return s.server.URL + "/api/v3/"
+}
+
+// Client returns a go-github client for the server
+func (s *Server) Client() *github.Client {
+	client := github.NewClient(nil)
+	client, _ = client.WithEnterpriseURLs(s.URL(), s.URL())
+	return client
+}
+
+// RequireToken makes the server reject requests not authenticated with the token
+func (s *Server) RequireToken(token string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.token = token
+}
+
+// SetUser sets the login of the authenticated user, octocat by default
+func (s *Server) SetUser(login string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.user = login
+}
+
+// AddRepository adds a repository and returns its ID. An ID is assigned if unset.
+func (s *Server) AddRepository(repository Repository) int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if repository.ID == 0 {
+		s.nextID++
+		repository.ID = s.nextID
+	} else if repository.ID > s.nextID {
+		s.nextID = repository.ID
+	}
+	if repository.Properties == nil {
+		repository.Properties = make(map[string]any)
+	}
+	s.repositories = append(s.repositories, &repository)
+	return repository.ID
+}
+
+// RenameRepository renames or transfers a repository. Requests for the old name are
+// redirected, like on GitHub.
+func (s *Server) RenameRepository(org, repo, newOrg, newRepo string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	repository := s.findRepository(org, repo)
+	if repository == nil {
+		return
+	}
+	s.redirects[strings.ToLower(org+"/"+repo)] = repository.ID
+	repository.Organization = newOrg
+	repository.Name = newRepo
+}
+
+// Repository returns a copy of a repository, or nil if it does not exist
+func (s *Server) Repository(org, repo string) *Repository {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	repository := s.findRepository(org, repo)
+	if repository == nil {
+		return nil
+	}
+	copied := *repository
+	copied.Properties = make(map[string]any, len(repository.Properties))
+	for name, value := range repository.Properties {
+		copied.Properties[name] = value
+	}
+	return &copied
+}
+
+// DefineProperty adds a custom property definition to an organization. Once an
+// organization has definitions, values are validated against them; organizations
+// without definitions accept any property.
+func (s *Server) DefineProperty(org string, definition *github.CustomProperty) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := strings.ToLower(org)
+	s.definitions[key] = append(s.definitions[key], definition)
+}
+
+// AddEnterprise registers the organizations of an enterprise for the GraphQL API
+func (s *Server) AddEnterprise(slug string, organizations ...string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.enterprises[slug] = organizations
+}
+
+// AddTeamRepository gives a team of the organization access to a repository with a
+// permission: pull, triage, push, maintain or admin. The team is created if needed.
+func (s *Server) AddTeamRepository(org, team, repo, permission string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	repository := s.findRepository(org, repo)
+	if repository == nil {
+		panic(fmt.Sprintf("githubtest: repository %s/%s not found", org, repo))
+	}
+	key := strings.ToLower(org + "/" + team)
+	if s.teams[key] == nil {
+		s.teams[key] = make(map[int64]string)
+	}
+	s.teams[key][repository.ID] = permission
+}
+
+// SetPageSize caps the number of items per page, to exercise pagination
+func (s *Server) SetPageSize(size int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.pageSize = size
+}
+
+// SetRateLimit sets the number of requests remaining before the rate limit is hit
+func (s *Server) SetRateLimit(remaining int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.rateRemaining = remaining
+}
+
+// FailRequests makes the next times requests matching the method and path fail with
+// the status. The path is the API path without the /api/v3 prefix, e.g.
+// /repos/org/repo/properties/values.
+func (s *Server) FailRequests(method, path string, status, times int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.failures = append(s.failures, &failure{method: method, path: path, status: status, times: times})
+}
+
+// Requests returns the requests served so far as "METHOD /path"
+func (s *Server) Requests() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return slices.Clone(s.requests)
+}
+
+func (s *Server) findRepository(org, repo string) *Repository {
+	for _, repository := range s.repositories {
+		if strings.EqualFold(repository.Organization, org) && strings.EqualFold(repository.Name, repo) {
+			return repository
+		}
+	}
+	return nil
+}
+
+func (s *Server) findRepositoryByID(id int64) *Repository {
+	for _, repository := range s.repositories {
+		if repository.ID == id {
+			return repository
+		}
+	}
+	return nil
+}
+
+func (s *Server) organizations() []string {
+	var organizations []string
+	for _, repository := range s.repositories {
+		if !slices.ContainsFunc(organizations, func(organization string) bool {
+			return strings.EqualFold(organization, repository.Organization)
+		}) {
+			organizations = append(organizations, repository.Organization)
+		}
+	}
+	for org := range s.definitions {
+		if !slices.ContainsFunc(organizations, func(organization string) bool {
+			return strings.EqualFold(organization, org)
+		}) {
+			organizations = append(organizations, org)
+		}
+	}
+	sort.Strings(organizations)
+	return organizations
+}
+
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	path := strings.TrimPrefix(r.URL.Path, "/api/v3")
+	s.requests = append(s.requests, r.Method+" "+path)
+
+	if s.token != "" && r.Header.Get("Authorization") != "Bearer "+s.token {
+		writeError(w, http.StatusUnauthorized, "Bad credentials")
+		return
+	}
+
+	for _, f := range s.failures {
+		if f.times > 0 && f.method == r.Method && f.path == path {
+			f.times--
+			writeError(w, f.status, http.StatusText(f.status))
+			return
+		}
+	}
+
+	if s.rateRemaining <= 0 {
+		s.writeRateLimit(w)
+		writeError(w, http.StatusForbidden, "API rate limit exceeded")
+		return
+	}
+
+	status, body := s.route(r, path)
+	if status == http.StatusMovedPermanently {
+		w.Header().Set("Location", body.(string))
+		w.WriteHeader(status)
+		return
+	}
+	if link, ok := body.(paginated); ok {
+		if link.header != "" {
+			w.Header().Set("Link", link.header)
+		}
+		body = link.items
+	}
+
+	if status == http.StatusOK && r.Method == http.MethodGet {
+		data, _ := json.Marshal(body)
+		hash := sha256.Sum256(data)
+		etag := `"` + hex.EncodeToString(hash[:8]) + `"`
+		w.Header().Set("ETag", etag)
+		if r.Header.Get("If-None-Match") == etag {
+			// Conditional requests answered with 304 do not count against the rate limit
+			s.writeRateLimit(w)
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+	}
+
+	s.rateRemaining--
+	s.writeRateLimit(w)
+	if status >= http.StatusBadRequest {
+		writeError(w, status, body.(string))
+		return
+	}
+	if body == nil {
+		w.WriteHeader(status)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(body)
+}
+
+func (s *Server) writeRateLimit(w http.ResponseWriter) {
+	w.Header().Set("X-RateLimit-Limit", strconv.Itoa(s.rateLimit))
+	w.Header().Set("X-RateLimit-Remaining", strconv.Itoa(max(s.rateRemaining, 0)))
+	w.Header().Set("X-RateLimit-Used", strconv.Itoa(s.rateLimit-max(s.rateRemaining, 0)))
+	w.Header().Set("X-RateLimit-Reset", strconv.FormatInt(s.rateReset.Unix(), 10))
+	w.Header().Set("X-RateLimit-Resource", "core")
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(map[string]string{
+		"message":           message,
+		"documentation_url": "https://docs.github.com/rest",
+	})
+}
+
+// paginated is a page of a list together with its Link header
+type paginated struct {
+	items  any
+	header string
+}
+
+// route serves a request and returns the status with either the response body or,
+// for errors, the error message
+func (s *Server) route(r *http.Request, path string) (int, any) {
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+
+	switch {
+	case r.Method == http.MethodPost && (path == "/graphql" || path == "/api/graphql"):
+		return s.graphql(r)
+
+	case r.Method == http.MethodGet && path == "/user":
+		return http.StatusOK, map[string]any{"login": s.user, "type": "User"}
+
+	case r.Method == http.MethodGet && path == "/user/orgs":
+		organizations := make([]map[string]string, 0)
+		for _, organization := range s.organizations() {
+			organizations = append(organizations, map[string]string{"login": organization})
+		}
+		return http.StatusOK, s.paginate(r, organizations)
+
+	case len(segments) == 2 && segments[0] == "repositories" && r.Method == http.MethodGet:
+		id, err := strconv.ParseInt(segments[1], 10, 64)
+		if err != nil {
+			return http.StatusNotFound, "Not Found"
+		}
+		repository := s.findRepositoryByID(id)
+		if repository == nil {
+			return http.StatusNotFound, "Not Found"
+		}
+		return http.StatusOK, repositoryJSON(repository)
+
+	case len(segments) >= 3 && segments[0] == "repos":
+		repository := s.findRepository(segments[1], segments[2])
+		if repository == nil {
+			if id, exists := s.redirects[strings.ToLower(segments[1]+"/"+segments[2])]; exists && r.Method == http.MethodGet {
+				return http.StatusMovedPermanently, fmt.Sprintf("%s/api/v3/repositories/%d%s", s.server.URL, id, strings.Join(append([]string{""}, segments[3:]...), "/"))
+			}
+			return http.StatusNotFound, "Not Found"
+		}
+		rest := strings.Join(segments[3:], "/")
+		switch {
+		case rest == "" && r.Method == http.MethodGet:
+			return http.StatusOK, repositoryJSON(repository)
+		case rest == "properties/values" && r.Method == http.MethodGet:
+			return http.StatusOK, propertyValues(repository)
+		case rest == "properties/values" && r.Method == http.MethodPatch:
+			var request struct {
+				Properties []*github.CustomPropertyValue `json:"properties"`
+			}
+			if err := decode(r, &request); err != nil {
+				return http.StatusBadRequest, err.Error()
+			}
+			if status, message := s.updateProperties([]*Repository{repository}, request.Properties); status != 0 {
+				return status, message
+			}
+			return http.StatusNoContent, nil
+		}
+
+	case len(segments) >= 3 && segments[0] == "orgs":
+		org := segments[1]
+		rest := strings.Join(segments[2:], "/")
+		switch {
+		case rest == "repos" && r.Method == http.MethodGet:
+			repositories := make([]map[string]any, 0)
+			for _, repository := range s.repositories {
+				if strings.EqualFold(repository.Organization, org) {
+					repositories = append(repositories, repositoryJSON(repository))
+				}
+			}
+			return http.StatusOK, s.paginate(r, repositories)
+		case len(segments) == 5 && segments[2] == "teams" && segments[4] == "repos" && r.Method == http.MethodGet:
+			permissions, exists := s.teams[strings.ToLower(org+"/"+segments[3])]
+			if !exists {
+				return http.StatusNotFound, "Not Found"
+			}
+			repositories := make([]map[string]any, 0)
+			for _, repository := range s.repositories {
+				if permission, exists := permissions[repository.ID]; exists {
+					repositoryWithPermissions := repositoryJSON(repository)
+					repositoryWithPermissions["permissions"] = teamPermissions(permission)
+					repositories = append(repositories, repositoryWithPermissions)
+				}
+			}
+			return http.StatusOK, s.paginate(r, repositories)
+		case rest == "properties/schema" && r.Method == http.MethodGet:
+			definitions := s.definitions[strings.ToLower(org)]
+			if definitions == nil {
+				definitions = []*github.CustomProperty{}
+			}
+			return http.StatusOK, definitions
+		case strings.HasPrefix(rest, "properties/schema/") && r.Method == http.MethodGet:
+			name := strings.TrimPrefix(rest, "properties/schema/")
+			if definition := s.definition(org, name); definition != nil {
+				return http.StatusOK, definition
+			}
+			return http.StatusNotFound, "Not Found"
+		case rest == "properties/values" && r.Method == http.MethodGet:
+			values := make([]*github.RepoCustomPropertyValue, 0)
+			for _, repository := range s.repositories {
+				if strings.EqualFold(repository.Organization, org) {
+					values = append(values, &github.RepoCustomPropertyValue{
+						RepositoryID:       repository.ID,
+						RepositoryName:     repository.Name,
+						RepositoryFullName: repository.FullName(),
+						Properties:         propertyValues(repository),
+					})
+				}
+			}
+			return http.StatusOK, s.paginate(r, values)
+		case rest == "properties/values" && r.Method == http.MethodPatch:
+			var request struct {
+				RepositoryNames []string                      `json:"repository_names"`
+				Properties      []*github.CustomPropertyValue `json:"properties"`
+			}
+			if err := decode(r, &request); err != nil {
+				return http.StatusBadRequest, err.Error()
+			}
+			var repositories []*Repository
+			for _, name := range request.RepositoryNames {
+				repository := s.findRepository(org, name)
+				if repository == nil {
+					return http.StatusNotFound, fmt.Sprintf("Repository %s not found", name)
+				}
+				repositories = append(repositories, repository)
+			}
+			if status, message := s.updateProperties(repositories, request.Properties); status != 0 {
+				return status, message
+			}
+			return http.StatusNoContent, nil
+		}
+	}
+
+	return http.StatusNotFound, "Not Found"
+}
+
+func decode(r *http.Request, v any) error {
+	data, err := io.ReadAll(r.Body)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("Problems parsing JSON")
+	}
+	return nil
+}
+
+// paginate returns the page of items requested with the page and per_page parameters
+func (s *Server) paginate(r *http.Request, items any) paginated {
+	list := toSlice(items)
+
+	perPage, _ := strconv.Atoi(r.URL.Query().Get("per_page"))
+	if perPage <= 0 {
+		perPage = 30
+	}
+	perPage = min(perPage, 100)
+	if s.pageSize > 0 {
+		perPage = min(perPage, s.pageSize)
+	}
+	page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+	if page <= 0 {
+		page = 1
+	}
+	lastPage := max((len(list)+perPage-1)/perPage, 1)
+
+	start := min((page-1)*perPage, len(list))
+	end := min(start+perPage, len(list))
+
+	var links []string
+	pageURL := func(p int) string {
+		u := url.URL{Scheme: "http", Host: r.Host, Path: r.URL.Path}
+		query := r.URL.Query()
+		query.Set("page", strconv.Itoa(p))
+		query.Set("per_page", strconv.Itoa(perPage))
+		u.RawQuery = query.Encode()
+		return u.String()
+	}
+	if page < lastPage {
+		links = append(links, fmt.Sprintf(`<%s>; rel="next"`, pageURL(page+1)), fmt.Sprintf(`<%s>; rel="last"`, pageURL(lastPage)))
+	}
+	if page > 1 {
+		links = append(links, fmt.Sprintf(`<%s>; rel="prev"`, pageURL(page-1)), fmt.Sprintf(`<%s>; rel="first"`, pageURL(1)))
+	}
+	return paginated{items: list[start:end], header: strings.Join(links, ", ")}
+}
+
+func toSlice(items any) []any {
+	data, _ := json.Marshal(items)
+	var list []any
+	_ = json.Unmarshal(data, &list)
+	if list == nil {
+		list = []any{}
+	}
+	return list
+}
+
+func (s *Server) definition(org, name string) *github.CustomProperty {
+	for _, definition := range s.definitions[strings.ToLower(org)] {
+		if definition.GetPropertyName() == name {
+			return definition
+		}
+	}
+	return nil
+}
+
+// updateProperties validates and applies property values to repositories. A null
+// value removes the property. It returns a non-zero status on failure.
+func (s *Server) updateProperties(repositories []*Repository, values []*github.CustomPropertyValue) (int, string) {
+	for _, repository := range repositories {
+		if repository.Archived {
+			return http.StatusForbidden, "Repository was archived so is read-only."
+		}
+		if repository.Disabled {
+			return http.StatusForbidden, "Repository access blocked"
+		}
+		if len(s.definitions[strings.ToLower(repository.Organization)]) == 0 {
+			continue
+		}
+		for _, value := range values {
+			definition := s.definition(repository.Organization, value.PropertyName)
+			if definition == nil {
+				return http.StatusUnprocessableEntity, fmt.Sprintf("Property %s does not exist", value.PropertyName)
+			}
+			if err := validateValue(definition, value.Value); err != nil {
+				return http.StatusUnprocessableEntity, err.Error()
+			}
+		}
+	}
+
+	for _, repository := range repositories {
+		for _, value := range values {
+			if value.Value == nil {
+				delete(repository.Properties, value.PropertyName)
+			} else {
+				repository.Properties[value.PropertyName] = value.Value
+			}
+		}
+	}
+	return 0, ""
+}
+
+func validateValue(definition *github.CustomProperty, value any) error {
+	if value == nil {
+		if definition.GetRequired() {
+			return fmt.Errorf("Property %s is required", definition.GetPropertyName())
+		}
+		return nil
+	}
+
+	switch definition.ValueType {
+	case "multi_select":
+		values, ok := value.([]string)
+		if !ok {
+			return fmt.Errorf("Value of property %s must be an array", definition.GetPropertyName())
+		}
+		for _, v := range values {
+			if !slices.Contains(definition.AllowedValues, v) {
+				return fmt.Errorf("Value %s is not allowed for property %s", v, definition.GetPropertyName())
+			}
+		}
+		return nil
+	}
+
+	s, ok := value.(string)
+	if !ok {
+		return fmt.Errorf("Value of property %s must be a string", definition.GetPropertyName())
+	}
+	switch definition.ValueType {
+	case "single_select":
+		if !slices.Contains(definition.AllowedValues, s) {
+			return fmt.Errorf("Value %s is not allowed for property %s", s, definition.GetPropertyName())
+		}
+	case "true_false":
+		if s != "true" && s != "false" {
+			return fmt.Errorf("Value %s is not allowed for property %s", s, definition.GetPropertyName())
+		}
+	}
+	return nil
+}
+
+func repositoryJSON(repository *Repository) map[string]any {
+	return map[string]any{
+		"id":        repository.ID,
+		"name":      repository.Name,
+		"full_name": repository.FullName(),
+		"owner": map[string]any{
+			"login": repository.Organization,
+			"type":  "Organization",
+		},
+		"archived":          repository.Archived,
+		"disabled":          repository.Disabled,
+		"is_template":       repository.IsTemplate,
+		"custom_properties": repository.Properties,
+	}
+}
+
+// teamPermissions returns the permissions map of a team's repository, in which all
+// permissions up to the given one are set
+func teamPermissions(permission string) map[string]bool {
+	permissions := make(map[string]bool)
+	granted := true
+	for _, level := range []string{"pull", "triage", "push", "maintain", "admin"} {
+		permissions[level] = granted
+		if level == permission {
+			granted = false
+		}
+	}
+	return permissions
+}
+
+func propertyValues(repository *Repository) []*github.CustomPropertyValue {
+	names := make([]string, 0, len(repository.Properties))
+	for name := range repository.Properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	values := make([]*github.CustomPropertyValue, 0, len(names))
+	for _, name := range names {
+		values = append(values, &github.CustomPropertyValue{PropertyName: name, Value: repository.Properties[name]})
+	}
+	return values
+}
+
+// graphql serves the enterprise organizations query used to expand enterprise selectors
+func (s *Server) graphql(r *http.Request) (int, any) {
+	var request struct {
+		Variables struct {
+			Slug string `json:"slug"`
+		} `json:"variables"`
+	}
+	if err := decode(r, &request); err != nil {
+		return http.StatusBadRequest, err.Error()
+	}
+
+	organizations, exists := s.enterprises[request.Variables.Slug]
+	if !exists {
+		return http.StatusOK, map[string]any{
+			"data":   map[string]any{"enterprise": nil},
+			"errors": []map[string]string{{"message": fmt.Sprintf("Could not resolve to an Enterprise with the slug '%s'.", request.Variables.Slug)}},
+		}
+	}
+
+	nodes := make([]map[string]string, 0, len(organizations))
+	for _, organization := range organizations {
+		nodes = append(nodes, map[string]string{"login": organization})
+	}
+	return http.StatusOK, map[string]any{
+		"data": map[string]any{
+			"enterprise": map[string]any{
+				"organizations": map[string]any{
+					"nodes":    nodes,
+					"pageInfo": map[string]any{"hasNextPage": false, "endCursor": nil},
+				},
+			},
+		},
+	}
+}
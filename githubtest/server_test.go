@@ -0,0 +1,75 @@
+package githubtest
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/google/go-github/v74/github"
+)
+
+func TestServerPagination(t *testing.T) {
+	server := NewServer(t)
+	server.SetPageSize(1)
+	server.AddRepository(Repository{Organization: "org1", Name: "repo1"})
+	server.AddRepository(Repository{Organization: "org1", Name: "repo2"})
+	client := server.Client()
+
+	repositories, resp, err := client.Repositories.ListByOrg(context.Background(), "org1", nil)
+	if err != nil {
+		t.Fatalf("ListByOrg failed: %v", err)
+	}
+	if len(repositories) != 1 || repositories[0].GetName() != "repo1" {
+		t.Errorf("expected repo1 on the first page, got %v", repositories)
+	}
+	if resp.NextPage != 2 || resp.LastPage != 2 {
+		t.Errorf("expected next and last page 2, got %d and %d", resp.NextPage, resp.LastPage)
+	}
+	if resp.Rate.Limit != 5000 || resp.Rate.Remaining != 4999 {
+		t.Errorf("expected rate limit headers, got %+v", resp.Rate)
+	}
+}
+
+func TestServerRequireToken(t *testing.T) {
+	server := NewServer(t)
+	server.RequireToken("secret")
+	server.AddRepository(Repository{Organization: "org1", Name: "repo1"})
+
+	_, resp, err := server.Client().Repositories.Get(context.Background(), "org1", "repo1")
+	if err == nil || resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("expected 401 without a token, got %v", err)
+	}
+
+	client := server.Client().WithAuthToken("secret")
+	if _, _, err := client.Repositories.Get(context.Background(), "org1", "repo1"); err != nil {
+		t.Errorf("expected the request with the token to succeed, got %v", err)
+	}
+}
+
+func TestServerOrganizationPropertyValues(t *testing.T) {
+	server := NewServer(t)
+	server.DefineProperty("org1", &github.CustomProperty{PropertyName: github.Ptr("langs"), ValueType: "multi_select", AllowedValues: []string{"go", "ts"}})
+	server.AddRepository(Repository{Organization: "org1", Name: "repo1"})
+	client := server.Client()
+	ctx := context.Background()
+
+	if _, err := client.Organizations.CreateOrUpdateRepoCustomPropertyValues(ctx, "org1", []string{"repo1"}, []*github.CustomPropertyValue{
+		{PropertyName: "langs", Value: []string{"go", "ts"}},
+	}); err != nil {
+		t.Fatalf("CreateOrUpdateRepoCustomPropertyValues failed: %v", err)
+	}
+
+	values, _, err := client.Organizations.ListCustomPropertyValues(ctx, "org1", nil)
+	if err != nil {
+		t.Fatalf("ListCustomPropertyValues failed: %v", err)
+	}
+	if len(values) != 1 || len(values[0].Properties) != 1 || values[0].Properties[0].PropertyName != "langs" {
+		t.Errorf("unexpected values %v", values)
+	}
+
+	if _, err := client.Organizations.CreateOrUpdateRepoCustomPropertyValues(ctx, "org1", []string{"repo1"}, []*github.CustomPropertyValue{
+		{PropertyName: "langs", Value: []string{"rust"}},
+	}); err == nil {
+		t.Error("expected an error for a value that is not allowed")
+	}
+}
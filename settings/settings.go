@@ -0,0 +1,89 @@
+package settings
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/goccy/go-yaml"
+)
+
+// Settings configures how the tool connects to GitHub. Unlike configuration files,
+// which describe desired property values, settings describe the GitHub instances
+// the organizations live on.
+type Settings struct {
+	// Hostname is the default GitHub host for all organizations
+	Hostname string `yaml:"hostname"`
+	// CABundle is a PEM file with additional trusted certificate authorities
+	CABundle string `yaml:"ca_bundle"`
+	// Proxy is the URL of an HTTPS proxy
+	Proxy string `yaml:"proxy"`
+	// Credentials are the default credentials for all organizations
+	Credentials `yaml:",inline"`
+	// Organizations holds per-organization overrides, keyed by organization name
+	Organizations map[string]OrganizationSettings `yaml:"organizations"`
+}
+
+// OrganizationSettings overrides settings for a single organization
+type OrganizationSettings struct {
+	// Hostname is the GitHub host of the organization
+	Hostname string `yaml:"hostname"`
+	// Credentials are the credentials for the organization
+	Credentials `yaml:",inline"`
+}
+
+// Credentials selects where the token is read from. When no field is set, the
+// default credential chain is used.
+type Credentials struct {
+	// TokenEnv is the name of an environment variable holding the token
+	TokenEnv string `yaml:"token_env"`
+	// TokenFile is the path of a file holding the token
+	TokenFile string `yaml:"token_file"`
+	// CredentialCommand is a command printing the token
+	CredentialCommand string `yaml:"credential_command"`
+}
+
+// IsZero reports whether no credential source is configured
+func (c Credentials) IsZero() bool {
+	return c == Credentials{}
+}
+
+// Load reads settings from YAML
+func Load(r io.Reader) (*Settings, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read settings: %w", err)
+	}
+
+	var settings Settings
+	if err := yaml.UnmarshalWithOptions(data, &settings, yaml.DisallowUnknownField()); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal settings: %w", err)
+	}
+	return &settings, nil
+}
+
+// Organization returns the overrides of an organization, matched case-insensitively
+func (s *Settings) Organization(org string) (OrganizationSettings, bool) {
+	for name, organizationSettings := range s.Organizations {
+		if strings.EqualFold(name, org) {
+			return organizationSettings, true
+		}
+	}
+	return OrganizationSettings{}, false
+}
+
+// HostnameFor returns the GitHub host of an organization, or an empty string for the default host
+func (s *Settings) HostnameFor(org string) string {
+	if organizationSettings, ok := s.Organization(org); ok && organizationSettings.Hostname != "" {
+		return organizationSettings.Hostname
+	}
+	return s.Hostname
+}
+
+// CredentialsFor returns the credentials of an organization, falling back to the default credentials
+func (s *Settings) CredentialsFor(org string) Credentials {
+	if organizationSettings, ok := s.Organization(org); ok && !organizationSettings.Credentials.IsZero() {
+		return organizationSettings.Credentials
+	}
+	return s.Credentials
+}
@@ -0,0 +1,93 @@
+package settings
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestLoad(t *testing.T) {
+	settings, err := Load(strings.NewReader(`hostname: ghe.example.com
+ca_bundle: /etc/ssl/corp-ca.pem
+proxy: http://proxy.example.com:8080
+organizations:
+  CloudOrg:
+    hostname: github.com
+  residency-org:
+    hostname: acme.ghe.com
+  plain-org: {}
+`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if settings.CABundle != "/etc/ssl/corp-ca.pem" || settings.Proxy != "http://proxy.example.com:8080" {
+		t.Errorf("unexpected settings: %+v", settings)
+	}
+
+	testCases := []struct {
+		org      string
+		expected string
+	}{
+		{"cloudorg", "github.com"},
+		{"residency-org", "acme.ghe.com"},
+		{"plain-org", "ghe.example.com"},
+		{"unknown-org", "ghe.example.com"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.org, func(t *testing.T) {
+			if hostname := settings.HostnameFor(tc.org); hostname != tc.expected {
+				t.Errorf("expected %q, got %q", tc.expected, hostname)
+			}
+		})
+	}
+}
+
+func TestLoadInvalid(t *testing.T) {
+	_, err := Load(strings.NewReader("hostnme: typo.example.com\n"))
+	if err == nil {
+		t.Fatal("expected error but got none")
+	}
+	if !strings.Contains(err.Error(), "failed to unmarshal settings") {
+		t.Errorf("expected error to contain 'failed to unmarshal settings', got %q", err.Error())
+	}
+}
+
+func TestCredentialsFor(t *testing.T) {
+	settings, err := Load(strings.NewReader(`token_env: DEFAULT_TOKEN
+organizations:
+  team-a:
+    hostname: ghe.example.com
+    token_env: TEAM_A_TOKEN
+  team-b:
+    token_file: /run/secrets/team-b
+  team-c:
+    credential_command: vault read -field=token secret/team-c
+  team-d:
+    hostname: ghe.example.com
+`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	testCases := []struct {
+		org      string
+		expected Credentials
+	}{
+		{"team-a", Credentials{TokenEnv: "TEAM_A_TOKEN"}},
+		{"Team-B", Credentials{TokenFile: "/run/secrets/team-b"}},
+		{"team-c", Credentials{CredentialCommand: "vault read -field=token secret/team-c"}},
+		{"team-d", Credentials{TokenEnv: "DEFAULT_TOKEN"}},
+		{"unknown", Credentials{TokenEnv: "DEFAULT_TOKEN"}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.org, func(t *testing.T) {
+			if credentials := settings.CredentialsFor(tc.org); credentials != tc.expected {
+				t.Errorf("expected %+v, got %+v", tc.expected, credentials)
+			}
+		})
+	}
+
+	if !(Credentials{}).IsZero() || (Credentials{TokenEnv: "X"}).IsZero() {
+		t.Error("unexpected IsZero result")
+	}
+}
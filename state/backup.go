@@ -0,0 +1,76 @@
+package state
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/google/go-github/v74/github"
+	"github.com/hi120ki/gh-custom-property-manager/config"
+)
+
+// NewBackup creates a snapshot of all repositories of whole organizations
+func NewBackup(hostname string, organizations []string, repositories []*github.Repository, createdAt time.Time) *Snapshot {
+	snapshot := &Snapshot{
+		Version:       Version,
+		CreatedAt:     createdAt.UTC(),
+		Hostname:      hostname,
+		Organizations: organizations,
+	}
+	snapshot.addRepositories(repositories)
+	return snapshot
+}
+
+// ConfigFiles converts the snapshot to configuration files restoring its values. For
+// each property set on any repository of the snapshot, repositories without a value
+// are configured with an empty value, which unsets the property. Properties that are
+// not in the snapshot are left untouched. Values other than strings, such as
+// multi_select values, cannot be configured and are returned as skipped.
+func (s *Snapshot) ConfigFiles() ([]*config.ConfigFile, []string) {
+	propertyNames := make(map[string]bool)
+	for _, repository := range s.Repositories {
+		for propertyName := range repository.CustomProperties {
+			propertyNames[propertyName] = true
+		}
+	}
+
+	var configFiles []*config.ConfigFile
+	var skipped []string
+	for propertyName := range propertyNames {
+		configFile := &config.ConfigFile{PropertyName: propertyName, Literal: true}
+		valueIndex := make(map[string]int)
+
+		for _, repository := range s.Repositories {
+			value := ""
+			if rawValue, exists := repository.CustomProperties[propertyName]; exists && rawValue != nil {
+				stringValue, ok := rawValue.(string)
+				if !ok {
+					skipped = append(skipped, fmt.Sprintf("%s: %s", repository.FullName(), propertyName))
+					continue
+				}
+				value = stringValue
+			}
+
+			index, exists := valueIndex[value]
+			if !exists {
+				index = len(configFile.Values)
+				valueIndex[value] = index
+				configFile.Values = append(configFile.Values, config.ValueConfig{Value: value})
+			}
+			configFile.Values[index].Repositories = append(configFile.Values[index].Repositories, config.RepositoryConfig{
+				Name: repository.FullName(),
+				ID:   repository.ID,
+			})
+		}
+
+		if len(configFile.Values) > 0 {
+			configFiles = append(configFiles, configFile)
+		}
+	}
+
+	sort.Slice(configFiles, func(i, j int) bool {
+		return configFiles[i].PropertyName < configFiles[j].PropertyName
+	})
+	sort.Strings(skipped)
+	return configFiles, skipped
+}
@@ -0,0 +1,82 @@
+package state
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/go-github/v74/github"
+	"github.com/hi120ki/gh-custom-property-manager/config"
+)
+
+func TestBackupConfigFiles(t *testing.T) {
+	repositories := []*github.Repository{
+		{ID: github.Ptr(int64(2)), Name: github.Ptr("repo2"), Owner: &github.User{Login: github.Ptr("org1")}, CustomProperties: map[string]any{"team": "frontend"}},
+		{ID: github.Ptr(int64(1)), Name: github.Ptr("repo1"), Owner: &github.User{Login: github.Ptr("org1")}, CustomProperties: map[string]any{"team": "backend", "langs": []any{"go", "ts"}}},
+		{ID: github.Ptr(int64(3)), Name: github.Ptr("repo3"), Owner: &github.User{Login: github.Ptr("org1")}},
+	}
+	snapshot := NewBackup("github.com", []string{"org1"}, repositories, time.Now())
+
+	if snapshot.Repositories[0].Name != "repo1" {
+		t.Errorf("expected repositories sorted by name, got %s first", snapshot.Repositories[0].Name)
+	}
+
+	configFiles, skipped := snapshot.ConfigFiles()
+	if len(skipped) != 1 || skipped[0] != "org1/repo1: langs" {
+		t.Errorf("expected the multi-select value to be skipped, got %v", skipped)
+	}
+	if len(configFiles) != 2 || configFiles[0].PropertyName != "langs" || configFiles[1].PropertyName != "team" {
+		t.Fatalf("expected config files for langs and team, got %v", configFiles)
+	}
+
+	expected := map[string]string{"org1/repo1": "backend", "org1/repo2": "frontend", "org1/repo3": ""}
+	for _, value := range configFiles[1].Values {
+		for _, repositoryConfig := range value.Repositories {
+			if expected[repositoryConfig.Name] != value.Value {
+				t.Errorf("expected %s = %q, got %q", repositoryConfig.Name, expected[repositoryConfig.Name], value.Value)
+			}
+			if repositoryConfig.ID == 0 {
+				t.Errorf("expected the ID of %s to be recorded", repositoryConfig.Name)
+			}
+		}
+	}
+}
+
+func TestRestoreDiffs(t *testing.T) {
+	// The backup was taken before team changed on repo1 and was set on repo3
+	backup := NewBackup("github.com", []string{"org1"}, []*github.Repository{
+		{ID: github.Ptr(int64(1)), Name: github.Ptr("repo1"), Owner: &github.User{Login: github.Ptr("org1")}, CustomProperties: map[string]any{"team": "backend"}},
+		{ID: github.Ptr(int64(3)), Name: github.Ptr("repo3"), Owner: &github.User{Login: github.Ptr("org1")}},
+	}, time.Now())
+	current := &Snapshot{Version: Version, Repositories: []*Repository{
+		{ID: 1, Organization: "org1", Name: "repo1", CustomProperties: map[string]any{"team": "frontend"}},
+		{ID: 3, Organization: "org1", Name: "repo3", CustomProperties: map[string]any{"team": "frontend"}},
+	}}
+
+	configManager := config.NewConfig(NewClient(current))
+	configFiles, _ := backup.ConfigFiles()
+	for _, configFile := range configFiles {
+		if err := configManager.AddConfigFile(configFile); err != nil {
+			t.Fatalf("AddConfigFile failed: %v", err)
+		}
+	}
+
+	ctx := context.Background()
+	if err := configManager.GenerateRepositories(ctx); err != nil {
+		t.Fatalf("GenerateRepositories failed: %v", err)
+	}
+	diffs, err := configManager.GenerateDiffs(ctx)
+	if err != nil {
+		t.Fatalf("GenerateDiffs failed: %v", err)
+	}
+
+	if len(diffs) != 2 {
+		t.Fatalf("expected 2 diffs, got %d", len(diffs))
+	}
+	if diffs[0].Repository != "repo1" || diffs[0].NewValue != "backend" {
+		t.Errorf("expected repo1 to be restored to backend, got %+v", diffs[0])
+	}
+	if diffs[1].Repository != "repo3" || diffs[1].NewValue != "" || diffs[1].OldValue != "frontend" {
+		t.Errorf("expected team to be unset on repo3, got %+v", diffs[1])
+	}
+}
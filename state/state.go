@@ -0,0 +1,213 @@
+package state
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/go-github/v74/github"
+	"github.com/hi120ki/gh-custom-property-manager/config"
+)
+
+// Version is the format version of snapshots written by this tool
+const Version = 1
+
+// Snapshot records the custom property values of repositories at a point in time.
+// Plans computed from a snapshot need neither network access nor credentials.
+type Snapshot struct {
+	Version   int       `json:"version"`
+	CreatedAt time.Time `json:"created_at"`
+	// Hostname is the GitHub host the snapshot was taken from
+	Hostname string `json:"hostname,omitempty"`
+	// Organizations are set for backups of whole organizations
+	Organizations []string `json:"organizations,omitempty"`
+	// Repositories are the repositories of the configuration files
+	Repositories []*Repository `json:"repositories"`
+	// Enterprises are the organizations of enterprises used in selectors, keyed by enterprise slug
+	Enterprises map[string][]string `json:"enterprises,omitempty"`
+}
+
+// Repository is the state of a single repository
+type Repository struct {
+	ID           int64  `json:"id"`
+	Organization string `json:"organization"`
+	Name         string `json:"name"`
+	// Aliases are configured names resolving to the repository, e.g. after a rename
+	Aliases          []string       `json:"aliases,omitempty"`
+	Archived         bool           `json:"archived,omitempty"`
+	Disabled         bool           `json:"disabled,omitempty"`
+	IsTemplate       bool           `json:"is_template,omitempty"`
+	CustomProperties map[string]any `json:"custom_properties"`
+}
+
+// FullName returns the 'org/repo' name of the repository
+func (r *Repository) FullName() string {
+	return r.Organization + "/" + r.Name
+}
+
+// New creates a snapshot of the repositories resolved by the config manager
+func New(configManager *config.Config, createdAt time.Time) *Snapshot {
+	snapshot := &Snapshot{
+		Version:     Version,
+		CreatedAt:   createdAt.UTC(),
+		Enterprises: configManager.EnterpriseOrganizations(),
+	}
+
+	aliases := make(map[string][]string)
+	for _, rename := range configManager.Renames() {
+		key := strings.ToLower(rename.CanonicalName)
+		aliases[key] = append(aliases[key], rename.ConfiguredName)
+	}
+
+	snapshot.addRepositories(configManager.Repositories())
+	for _, repository := range snapshot.Repositories {
+		repository.Aliases = aliases[strings.ToLower(repository.FullName())]
+	}
+	return snapshot
+}
+
+// addRepositories records the state of repositories, sorted by name
+func (s *Snapshot) addRepositories(repositories []*github.Repository) {
+	for _, repository := range repositories {
+		customProperties := repository.CustomProperties
+		if customProperties == nil {
+			customProperties = map[string]any{}
+		}
+		s.Repositories = append(s.Repositories, &Repository{
+			ID:               repository.GetID(),
+			Organization:     repository.GetOwner().GetLogin(),
+			Name:             repository.GetName(),
+			Archived:         repository.GetArchived(),
+			Disabled:         repository.GetDisabled(),
+			IsTemplate:       repository.GetIsTemplate(),
+			CustomProperties: customProperties,
+		})
+	}
+	sort.Slice(s.Repositories, func(i, j int) bool {
+		return strings.ToLower(s.Repositories[i].FullName()) < strings.ToLower(s.Repositories[j].FullName())
+	})
+}
+
+// Load reads a snapshot
+func Load(r io.Reader) (*Snapshot, error) {
+	decoder := json.NewDecoder(r)
+	decoder.DisallowUnknownFields()
+
+	var snapshot Snapshot
+	if err := decoder.Decode(&snapshot); err != nil {
+		return nil, fmt.Errorf("failed to parse state snapshot: %w", err)
+	}
+	if snapshot.Version != Version {
+		return nil, fmt.Errorf("unsupported state snapshot version %d (expected %d)", snapshot.Version, Version)
+	}
+	return &snapshot, nil
+}
+
+// Write writes the snapshot as indented JSON
+func (s *Snapshot) Write(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(s); err != nil {
+		return fmt.Errorf("failed to write state snapshot: %w", err)
+	}
+	return nil
+}
+
+// Client serves repositories from a snapshot. It implements config.GitHubClient,
+// so plans can be computed offline.
+type Client struct {
+	snapshot *Snapshot
+}
+
+// NewClient creates a client reading from the snapshot
+func NewClient(snapshot *Snapshot) *Client {
+	return &Client{snapshot: snapshot}
+}
+
+func (c *Client) GetRepository(ctx context.Context, org, repo string) *github.Repository {
+	name := org + "/" + repo
+	for _, repository := range c.snapshot.Repositories {
+		if strings.EqualFold(repository.FullName(), name) {
+			return repository.toGitHub()
+		}
+	}
+	for _, repository := range c.snapshot.Repositories {
+		for _, alias := range repository.Aliases {
+			if strings.EqualFold(alias, name) {
+				return repository.toGitHub()
+			}
+		}
+	}
+	return nil
+}
+
+func (c *Client) GetRepositoryByID(ctx context.Context, org string, id int64) *github.Repository {
+	for _, repository := range c.snapshot.Repositories {
+		if repository.ID == id {
+			return repository.toGitHub()
+		}
+	}
+	return nil
+}
+
+func (c *Client) UpdateCustomProperties(ctx context.Context, org, repo string, properties map[string]string) error {
+	return fmt.Errorf("cannot update %s/%s: state snapshots are read-only", org, repo)
+}
+
+// ListOrganizations returns the organizations with repositories in the snapshot
+func (c *Client) ListOrganizations(ctx context.Context) ([]string, error) {
+	var organizations []string
+	seen := make(map[string]bool)
+	for _, repository := range c.snapshot.Repositories {
+		if key := strings.ToLower(repository.Organization); !seen[key] {
+			seen[key] = true
+			organizations = append(organizations, repository.Organization)
+		}
+	}
+	return organizations, nil
+}
+
+func (c *Client) ListEnterpriseOrganizations(ctx context.Context, enterprise string) ([]string, error) {
+	organizations, exists := c.snapshot.Enterprises[enterprise]
+	if !exists {
+		return nil, fmt.Errorf("enterprise %s is not in the state snapshot", enterprise)
+	}
+	return organizations, nil
+}
+
+func (c *Client) ListRepositories(ctx context.Context, org string) ([]*github.Repository, error) {
+	var repositories []*github.Repository
+	for _, repository := range c.snapshot.Repositories {
+		if strings.EqualFold(repository.Organization, org) {
+			repositories = append(repositories, repository.toGitHub())
+		}
+	}
+	return repositories, nil
+}
+
+// ListTeamRepositories fails, as snapshots do not record team access
+func (c *Client) ListTeamRepositories(ctx context.Context, org, team string) ([]*github.Repository, error) {
+	return nil, fmt.Errorf("team %s/%s cannot be resolved offline, as snapshots do not record team access", org, team)
+}
+
+// toGitHub converts the recorded state to the repository returned by the GitHub API
+func (r *Repository) toGitHub() *github.Repository {
+	customProperties := make(map[string]any, len(r.CustomProperties))
+	for name, value := range r.CustomProperties {
+		customProperties[name] = value
+	}
+	return &github.Repository{
+		ID:               github.Ptr(r.ID),
+		Name:             github.Ptr(r.Name),
+		FullName:         github.Ptr(r.FullName()),
+		Owner:            &github.User{Login: github.Ptr(r.Organization)},
+		Archived:         github.Ptr(r.Archived),
+		Disabled:         github.Ptr(r.Disabled),
+		IsTemplate:       github.Ptr(r.IsTemplate),
+		CustomProperties: customProperties,
+	}
+}
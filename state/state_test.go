@@ -0,0 +1,133 @@
+package state
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hi120ki/gh-custom-property-manager/config"
+)
+
+func testSnapshot() *Snapshot {
+	return &Snapshot{
+		Version:   Version,
+		CreatedAt: time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC),
+		Repositories: []*Repository{
+			{ID: 1, Organization: "org1", Name: "repo1", CustomProperties: map[string]any{"team": "backend"}},
+			{ID: 2, Organization: "org1", Name: "renamed", Aliases: []string{"org1/old-name"}, CustomProperties: map[string]any{}},
+			{ID: 3, Organization: "org2", Name: "repo3", Archived: true, CustomProperties: map[string]any{}},
+		},
+		Enterprises: map[string][]string{"acme": {"org1", "org2"}},
+	}
+}
+
+func TestClient(t *testing.T) {
+	client := NewClient(testSnapshot())
+	ctx := context.Background()
+
+	if repository := client.GetRepository(ctx, "ORG1", "repo1"); repository == nil || repository.GetID() != 1 {
+		t.Errorf("expected org1/repo1, got %v", repository)
+	}
+	if repository := client.GetRepository(ctx, "org1", "old-name"); repository == nil || repository.GetName() != "renamed" {
+		t.Errorf("expected the alias to resolve to org1/renamed, got %v", repository)
+	}
+	if repository := client.GetRepositoryByID(ctx, "org2", 3); repository == nil || !repository.GetArchived() {
+		t.Errorf("expected archived org2/repo3, got %v", repository)
+	}
+	if client.GetRepository(ctx, "org1", "missing") != nil {
+		t.Error("expected org1/missing not to be found")
+	}
+
+	organizations, _ := client.ListOrganizations(ctx)
+	if strings.Join(organizations, ",") != "org1,org2" {
+		t.Errorf("expected org1,org2, got %v", organizations)
+	}
+	if _, err := client.ListEnterpriseOrganizations(ctx, "unknown"); err == nil {
+		t.Error("expected error for an enterprise missing from the snapshot")
+	}
+	if repositories, _ := client.ListRepositories(ctx, "org1"); len(repositories) != 2 {
+		t.Errorf("expected 2 repositories in org1, got %d", len(repositories))
+	}
+	if err := client.UpdateCustomProperties(ctx, "org1", "repo1", map[string]string{"team": "frontend"}); err == nil {
+		t.Error("expected updates to be rejected")
+	}
+}
+
+func TestSnapshotRoundTrip(t *testing.T) {
+	configManager := config.NewConfig(NewClient(testSnapshot()))
+	if err := configManager.LoadConfig(strings.NewReader(`property_name: "team"
+values:
+  - value: "frontend"
+    repositories:
+      - name: "org1/repo1"
+      - name: "org1/old-name"`)); err != nil {
+		t.Fatalf("LoadConfig failed: %v", err)
+	}
+	if err := configManager.GenerateRepositories(context.Background()); err != nil {
+		t.Fatalf("GenerateRepositories failed: %v", err)
+	}
+
+	createdAt := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	snapshot := New(configManager, createdAt)
+
+	var buf bytes.Buffer
+	if err := snapshot.Write(&buf); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	loaded, err := Load(&buf)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	if !loaded.CreatedAt.Equal(createdAt) {
+		t.Errorf("expected created_at %v, got %v", createdAt, loaded.CreatedAt)
+	}
+	if len(loaded.Repositories) != 2 {
+		t.Fatalf("expected 2 repositories, got %d", len(loaded.Repositories))
+	}
+	if loaded.Repositories[0].FullName() != "org1/renamed" || strings.Join(loaded.Repositories[0].Aliases, ",") != "org1/old-name" {
+		t.Errorf("expected org1/renamed with alias org1/old-name, got %+v", loaded.Repositories[0])
+	}
+	if loaded.Repositories[1].CustomProperties["team"] != "backend" {
+		t.Errorf("expected team=backend on org1/repo1, got %v", loaded.Repositories[1].CustomProperties)
+	}
+
+	// Planning against the loaded snapshot sees the same state
+	offline := config.NewConfig(NewClient(loaded))
+	if err := offline.LoadConfig(strings.NewReader(`property_name: "team"
+values:
+  - value: "frontend"
+    repositories:
+      - name: "org1/repo1"
+      - name: "org1/old-name"`)); err != nil {
+		t.Fatalf("LoadConfig failed: %v", err)
+	}
+	ctx := context.Background()
+	if err := offline.GenerateRepositories(ctx); err != nil {
+		t.Fatalf("GenerateRepositories failed: %v", err)
+	}
+	diffs, err := offline.GenerateDiffs(ctx)
+	if err != nil {
+		t.Fatalf("GenerateDiffs failed: %v", err)
+	}
+	if len(diffs) != 2 {
+		t.Errorf("expected 2 diffs, got %d", len(diffs))
+	}
+}
+
+func TestLoadInvalid(t *testing.T) {
+	tests := map[string]string{
+		"invalid json":    `{`,
+		"unknown field":   `{"version": 1, "extra": true}`,
+		"unknown version": `{"version": 2, "repositories": []}`,
+	}
+	for name, input := range tests {
+		t.Run(name, func(t *testing.T) {
+			if _, err := Load(strings.NewReader(input)); err == nil {
+				t.Error("expected error")
+			}
+		})
+	}
+}